@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/amanzanero/yt/youtube"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+var (
+	claimsStatus   string
+	claimsShipping string
+	claimsOutput   string
+)
+
+func init() {
+	winnersCmd := &cobra.Command{
+		Use:   "winners",
+		Short: "Track whether winners claimed their prize",
+		Long: `These commands track the winners of past draws saved in ~/.yt/draws, or saved with --record
+elsewhere and listed in ~/.yt/draws.index.
+A winner is pending until marked claimed or expired. With a claim deadline, set
+by --claim-days or claimDays, pending winners past it show up as expired and can
+be rerolled with "yt winner reroll". The youtube API is not called.`,
+	}
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the winners of past draws and their claims",
+		Example: "yt giveaway winners list --status expired",
+		Args:    cobra.NoArgs,
+		Run:     claimsListCmd,
+	}
+	listCmd.Flags().StringVar(&claimsStatus, "status", "", "only list winners with this status: pending, claimed or expired")
+	claimCmd := &cobra.Command{
+		Use:   "claim <draw> <winner>...",
+		Short: "Mark winners as having claimed their prize",
+		Long: `This command marks winners as having claimed their prize. The draw is a record ID or path and
+winners are named by channel ID or display name. Run it again to update the shipping status.`,
+		Example: `yt giveaway winners claim 20210801-150405-a1b2c3 UCxyz --shipping "shipped, tracking 1Z999"`,
+		Args:    cobra.MinimumNArgs(2),
+		Run: func(_ *cobra.Command, args []string) {
+			setClaims(args[0], args[1:], youtube.ClaimClaimed)
+		},
+	}
+	claimCmd.Flags().StringVar(&claimsShipping, "shipping", "", "shipping status of the prize")
+	expireCmd := &cobra.Command{
+		Use:     "expire <draw> <winner>...",
+		Short:   "Mark winners as having missed their claim",
+		Example: "yt giveaway winners expire 20210801-150405-a1b2c3 UCxyz",
+		Args:    cobra.MinimumNArgs(2),
+		Run: func(_ *cobra.Command, args []string) {
+			setClaims(args[0], args[1:], youtube.ClaimExpired)
+		},
+	}
+	exportCmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Export the winners of past draws and their claims as CSV",
+		Example: "yt giveaway winners export --output winners.csv",
+		Args:    cobra.NoArgs,
+		Run:     claimsExportCmd,
+	}
+	exportCmd.Flags().StringVarP(&claimsOutput, "output", "o", "", "file to write, standard output when empty")
+	exportCmd.Flags().StringVar(&claimsStatus, "status", "", "only export winners with this status: pending, claimed or expired")
+
+	winnersCmd.AddCommand(listCmd, claimCmd, expireCmd, exportCmd)
+	giveawayCommand.AddCommand(winnersCmd)
+}
+
+// winnerClaims returns the claims of every saved draw matching --status.
+func winnerClaims() ([]*youtube.WinnerClaim, error) {
+	if claimsStatus != "" {
+		if err := youtube.ValidateClaimStatus(claimsStatus); err != nil {
+			return nil, err
+		}
+	}
+	records, err := youtube.ReadDrawRecords()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	claims := make([]*youtube.WinnerClaim, 0)
+	for _, record := range records {
+		for _, claim := range record.WinnerClaims(now) {
+			if claimsStatus == "" || claim.Status == claimsStatus {
+				claims = append(claims, claim)
+			}
+		}
+	}
+	return claims, nil
+}
+
+func claimsListCmd(_ *cobra.Command, _ []string) {
+	claims, err := winnerClaims()
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	if len(claims) == 0 {
+		fmt.Println("no winners")
+		return
+	}
+
+	expired := 0
+	drawId := ""
+	for _, claim := range claims {
+		if claim.DrawId != drawId {
+			drawId = claim.DrawId
+			fmt.Printf("%s\n", drawId)
+		}
+		line := fmt.Sprintf("  %-8s \"%s\" (%s)", claim.Status, claim.Winner.AuthorDisplayName, claim.Winner.AuthorChannelId)
+		if claim.Winner.Prize != "" {
+			line += " " + claim.Winner.Prize
+		}
+		if claim.Deadline != nil && claim.Status != youtube.ClaimClaimed {
+			line += fmt.Sprintf(", claim by %s", claim.Deadline.Local().Format("2006-01-02 15:04"))
+		}
+		if claim.Shipping != "" {
+			line += ", shipping: " + claim.Shipping
+		}
+
+		switch claim.Status {
+		case youtube.ClaimClaimed:
+			color.Green(line)
+		case youtube.ClaimExpired:
+			color.Red(line)
+			expired++
+		default:
+			fmt.Println(line)
+		}
+	}
+	if expired > 0 {
+		color.Yellow("%d expired winners, replace them with \"yt winner reroll <draw> <winner> --reason ...\"", expired)
+	}
+}
+
+func setClaims(draw string, winners []string, status string) {
+	path := youtube.FindDrawRecord(draw)
+	record, err := youtube.ReadDrawRecord(path)
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+
+	updated := 0
+	for _, who := range winners {
+		winner, err := record.SetClaim(who, status, claimsShipping)
+		if err != nil {
+			color.Red("yt: %v", err)
+			continue
+		}
+		fmt.Printf("\"%s\": %s\n", winner.AuthorDisplayName, status)
+		updated++
+	}
+	if updated == 0 {
+		os.Exit(1)
+	}
+
+	if _, err := record.Save(path); err != nil {
+		color.Red("yt: could not save draw record: %v", err)
+		os.Exit(1)
+	}
+}
+
+func claimsExportCmd(_ *cobra.Command, _ []string) {
+	claims, err := winnerClaims()
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	data, err := youtube.ClaimsCsv(claims)
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+
+	if claimsOutput == "" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(claimsOutput, data, 0644); err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%d winners exported to %s\n", len(claims), claimsOutput)
+}
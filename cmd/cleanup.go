@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	yt "github.com/amanzanero/yt/youtube"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"google.golang.org/api/youtube/v3"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	deleteAction   = "delete"
+	markSpamAction = "mark-spam"
+)
+
+// cleanupFlags are the flags of a cleanup command. Every command gets its own
+// so that they do not share state.
+type cleanupFlags struct {
+	selector yt.CommentSelector
+	channel  string
+	after    string
+	before   string
+	yes      bool
+}
+
+func init() {
+	for _, action := range []struct {
+		name  string
+		short string
+		verb  string
+	}{
+		{deleteAction, "Delete the selected comments", "deletes them"},
+		{markSpamAction, "Mark the selected comments as spam", "marks them as spam"},
+	} {
+		action := action
+		flags := new(cleanupFlags)
+		localCmd := &cobra.Command{
+			Use:   action.name + " [<video url>]",
+			Short: action.short,
+			Long: `This command selects comments and ` + action.verb + ` after a preview.
+
+Comments are selected from a video, or with --channel from every video of a
+channel, replies included. Without either, only the comments given with --id
+are looked up. A comment is selected when it matches every selector given:
+--id, --author, --match, --after and --before. The selected comments are always
+printed first, and nothing happens until that is confirmed unless --yes is given.`,
+			Example: `yt comments ` + action.name + ` --channel mine --after 48h --match '(?i)crypto|whatsapp'
+yt comments ` + action.name + ` --author UCxyz https://www.youtube.com/watch?v=oYBGPVwNK2c
+yt comments ` + action.name + ` --id Ugx1a2b3c4 --id Ugy5d6e7f8 --yes`,
+			Args: func(cmd *cobra.Command, args []string) error {
+				if flags.channel != "" {
+					return cobra.NoArgs(cmd, args)
+				}
+				return cobra.MaximumNArgs(1)(cmd, args)
+			},
+			Run: func(_ *cobra.Command, args []string) {
+				cleanupCmd(action.name, flags, args)
+			},
+		}
+		localCmd.Flags().StringVar(&flags.channel, "channel", "", "select comments across every video of this channel")
+		localCmd.Flags().StringSliceVar(&flags.selector.Ids, "id", nil, "select comments with these IDs (repeatable)")
+		localCmd.Flags().StringSliceVar(&flags.selector.Authors, "author", nil, "select comments by these author channel IDs (repeatable)")
+		localCmd.Flags().StringVar(&flags.selector.Match, "match", "", "select comments matching this regular expression")
+		localCmd.Flags().StringVar(&flags.after, "after", "", "select comments newer than this duration, like 48h, or published after this time")
+		localCmd.Flags().StringVar(&flags.before, "before", "", "select comments published before this time")
+		localCmd.Flags().BoolVarP(&flags.yes, "yes", "y", false, "do not ask for confirmation after the preview")
+		commentsCommand.AddCommand(localCmd)
+	}
+}
+
+func cleanupCmd(action string, flags *cleanupFlags, args []string) {
+	var err error
+	if flags.selector.After, err = parseSince(flags.after); err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	if flags.selector.Before, err = parseTime(flags.before, ""); err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	if err := flags.selector.Validate(); err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	if len(args) == 0 && flags.channel == "" && len(flags.selector.Ids) == 0 {
+		color.Red("yt: give a video, a --channel or comment IDs to select from")
+		os.Exit(1)
+	}
+
+	tokenProvider, youtubeService := newService()
+
+	start := time.Now()
+	comments, err := flags.selectComments(youtubeService, args)
+	fmt.Printf("took %dms\n", time.Since(start).Milliseconds())
+	if err != nil {
+		color.Red("yt: %v", err)
+		saveToken(tokenProvider)
+		os.Exit(1)
+	}
+	if len(comments) == 0 {
+		fmt.Println("no comments selected")
+		saveToken(tokenProvider)
+		return
+	}
+
+	for i, comment := range comments {
+		color.Yellow("%d: %s", i+1, comment.Id)
+		printComment("   ", "   ", comment)
+	}
+	if !flags.yes && !confirm(fmt.Sprintf("%s %d comments?", action, len(comments))) {
+		fmt.Println("nothing changed")
+		saveToken(tokenProvider)
+		return
+	}
+
+	done := 0
+	switch action {
+	case deleteAction:
+		for _, comment := range comments {
+			if err := youtubeService.DeleteComment(comment.Id); err != nil {
+				color.Red("yt: could not delete %s: %v", comment.Id, err)
+				if yt.IsFatal(err) {
+					// the rest would fail the same way, and count against the quota
+					break
+				}
+				continue
+			}
+			done++
+		}
+	case markSpamAction:
+		ids := make([]string, 0, len(comments))
+		for _, comment := range comments {
+			ids = append(ids, comment.Id)
+		}
+		if done, err = youtubeService.MarkAsSpam(ids); err != nil {
+			color.Red("yt: %v", err)
+		}
+	}
+
+	saveToken(tokenProvider)
+	if done < len(comments) {
+		color.Red("%s: %d of %d comments failed or were not attempted", action, len(comments)-done, len(comments))
+		os.Exit(1)
+	}
+	color.Green("%s: %d comments", action, len(comments))
+}
+
+// selectComments lists the comments in scope and applies the selector to them.
+func (flags *cleanupFlags) selectComments(youtubeService *yt.Service, args []string) ([]*youtube.Comment, error) {
+	if len(args) == 0 && flags.channel == "" {
+		comments, err := youtubeService.GetComments(flags.selector.Ids)
+		if err != nil {
+			return nil, err
+		}
+		threads := make([]*yt.CommentThread, 0, len(comments))
+		for _, comment := range comments {
+			threads = append(threads, &yt.CommentThread{Comment: comment})
+		}
+		return flags.selector.Select(threads)
+	}
+
+	// every thread is read, replies to old threads can be recent
+	opts := yt.CommentOptions{Replies: true}
+	var threads []*yt.CommentThread
+	var err error
+	if flags.channel != "" {
+		threads, err = youtubeService.ListChannelComments(flags.channel, opts)
+	} else {
+		threads, err = youtubeService.ListComments(args[0], opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return flags.selector.Select(threads)
+}
+
+// confirm asks a yes or no question, anything but yes is a no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
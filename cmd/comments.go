@@ -2,58 +2,203 @@ package cmd
 
 import (
 	"fmt"
-	"github.com/amanzanero/yt/youtube"
+	yt "github.com/amanzanero/yt/youtube"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"google.golang.org/api/youtube/v3"
 	"os"
+	"strings"
 	"time"
 )
 
-var count int
+var (
+	commentOptions yt.CommentOptions
+	commentChannel string
+	commentSince   string
+
+	classifyFile  string
+	ownerNames    []string
+	ownerIds      []string
+	spamThreshold float64
+	classifier    *yt.Classifier
+)
+
+var commentsCommand = &cobra.Command{
+	Use:   "comments",
+	Short: "Print out youtube comments",
+	Long: `This command prints out all comments for a given youtube video
+
+With --replies every reply is fetched too, and each comment is printed with its
+replies indented below it.
+
+--order, --search and --format are passed on to the API: it sorts the comments,
+only returns the ones matching the search terms and renders their text as plain
+text or HTML. Without --format the original text of each comment is printed.
+
+With --channel the comments on every video of a channel are listed instead,
+each labeled with its video. The channel is "mine", a channel ID or a channel
+URL. --since keeps only comments newer than a duration like 48h or a time, and
+--count 0 lists every comment since then.
+
+With --classify each comment is scored by a spam classifier trained on past
+moderation decisions, without any outside service. The training file is a CSV
+with a label column of approve, reject or ban, a text column and optionally an
+authorDisplayName and authorChannelId columns. Authors whose names imitate
+--owner-name, by default the name of your channel, are more likely to be scored
+as spam once the training data has impersonators in it. Comments from
+--owner-channel-id, by default your channel, never count as impersonation.
+Without owner channel IDs, an owner's exact name does not count either, since
+it cannot be told apart from the owner.`,
+	Example: `yt comments https://www.youtube.com/watch?v=BIk1zUy8ehU&ab_channel=LexFridman
+yt comments --replies -c 5 https://www.youtube.com/watch?v=BIk1zUy8ehU
+yt comments --order relevance --search "audio problem" https://www.youtube.com/watch?v=BIk1zUy8ehU
+yt comments --channel mine --since 48h --count 0
+yt comments --classify decisions.csv --replies https://www.youtube.com/watch?v=BIk1zUy8ehU`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if commentChannel != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: commentsCmd,
+}
 
 func init() {
-	localCmd := &cobra.Command{
-		Use:     "comments",
-		Short:   "Print out youtube comments",
-		Long:    `This command prints out all comments for a given youtube video`,
-		Example: "yt comments https://www.youtube.com/watch?v=BIk1zUy8ehU&ab_channel=LexFridman",
-		Args:    cobra.MinimumNArgs(1),
-		Run:     commentsCmd,
-	}
-	localCmd.Flags().IntVarP(&count, "count", "c", 10, "max number of comments")
+	localCmd := commentsCommand
+	localCmd.Flags().IntVarP(&commentOptions.Count, "count", "c", 10, "max number of comments, 0 for no limit")
+	localCmd.Flags().BoolVar(&commentOptions.Replies, "replies", false, "also print every reply as an indented tree")
+	localCmd.Flags().StringVar(&commentOptions.Order, "order", "", "order of the comments: time or relevance (default time)")
+	localCmd.Flags().StringVar(&commentOptions.Search, "search", "", "only print comments containing these search terms")
+	localCmd.Flags().StringVar(&commentOptions.Format, "format", "", "format of the printed text: plain or html")
+	localCmd.Flags().StringVar(&commentChannel, "channel", "", "list comments across every video of this channel instead")
+	localCmd.Flags().StringVar(&commentSince, "since", "", "only list comments newer than this duration, like 48h, or published after this time")
+	localCmd.Flags().StringVar(&classifyFile, "classify", "", "score comments for spam with a classifier trained on this CSV of past decisions")
+	localCmd.Flags().StringArrayVar(&ownerNames, "owner-name", nil, "with --classify, channel name impersonators imitate (default your channel's name, repeatable)")
+	localCmd.Flags().StringArrayVar(&ownerIds, "owner-channel-id", nil, "with --classify, channel ID of an owner, never scored as an impersonator (default your channel's ID with no --owner-name, repeatable)")
+	localCmd.Flags().Float64Var(&spamThreshold, "spam-threshold", 0.5, "with --classify, spam probability from which comments are marked as spam")
 	rootCmd.AddCommand(localCmd)
 }
 
 func commentsCmd(_ *cobra.Command, args []string) {
-	tokenProvider, err := youtube.NewTokenProvider(config)
-	cobra.CheckErr(err)
-
-	youtubeService, err := youtube.New(youtube.WithTokenSource(tokenProvider))
+	since, err := parseSince(commentSince)
 	if err != nil {
 		color.Red("yt: %v", err)
 		os.Exit(1)
 	}
+	commentOptions.Since = since
+	var examples []*yt.LabeledComment
+	if classifyFile != "" {
+		if examples, err = yt.ReadLabeledComments(classifyFile); err != nil {
+			color.Red("yt: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	tokenProvider, youtubeService := newService()
+
+	if classifyFile != "" {
+		if classifier, err = trainClassifier(youtubeService, examples); err != nil {
+			color.Red("yt: %v", err)
+			saveToken(tokenProvider)
+			os.Exit(1)
+		}
+	}
 
 	start := time.Now()
-	comments, err := youtubeService.ListComments(args[0], count)
+	var comments []*yt.CommentThread
+	if commentChannel != "" {
+		comments, err = youtubeService.ListChannelComments(commentChannel, commentOptions)
+	} else {
+		comments, err = youtubeService.ListComments(args[0], commentOptions)
+	}
 	stop := time.Since(start).Milliseconds()
 	if err != nil {
 		color.Red("yt: %v", err)
 	}
 	for i, comment := range comments {
-		fmt.Printf("%d: [%s] %s\n", i+1, comment.AuthorDisplayName, comment.TextOriginal)
+		if commentChannel != "" {
+			printVideoLabel(comment)
+		}
+		printComment(classify(fmt.Sprintf("%d: ", i+1), comment.Comment), "", comment.Comment)
+		for j, reply := range comment.Replies {
+			if j == len(comment.Replies)-1 {
+				printComment(classify("   └─ ", reply), "      ", reply)
+			} else {
+				printComment(classify("   ├─ ", reply), "   │  ", reply)
+			}
+		}
 	}
 	fmt.Printf("took %dms\n", stop)
 
-	// store token in case it refreshed
-	token, err := tokenProvider.Token()
-	if err != nil {
-		color.Red("yt: could not save token")
-		os.Exit(1)
+	saveToken(tokenProvider)
+}
+
+// printComment prints the comment after prefix, indenting its continuation
+// lines with indent so replies stay in their branch of the tree.
+func printComment(prefix string, indent string, comment *youtube.Comment) {
+	text := comment.Snippet.TextOriginal
+	if commentOptions.Format != "" {
+		text = comment.Snippet.TextDisplay
 	}
-	err = youtube.WriteToken(token)
-	if err != nil {
-		color.Red("yt: could not save token")
-		os.Exit(1)
+	text = strings.ReplaceAll(text, "\n", "\n"+indent)
+	fmt.Printf("%s[%s] %s\n", prefix, comment.Snippet.AuthorDisplayName, text)
+}
+
+// printVideoLabel prints the video a channel comment was left on.
+func printVideoLabel(comment *yt.CommentThread) {
+	switch {
+	case comment.VideoId == "":
+		color.Cyan("(channel)")
+	case comment.VideoTitle == "":
+		color.Cyan("(%s)", comment.VideoId)
+	default:
+		color.Cyan("(%s) %s", comment.VideoId, comment.VideoTitle)
+	}
+}
+
+// parseSince parses a time cutoff given either as a duration before now or as
+// a time.
+func parseSince(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		since := time.Now().Add(-duration)
+		return &since, nil
+	}
+	return parseTime(value, "")
+}
+
+// trainClassifier trains the spam classifier, looking up the name and ID of
+// the user's channel when no owner names are given.
+func trainClassifier(youtubeService *yt.Service, examples []*yt.LabeledComment) (*yt.Classifier, error) {
+	names, ids := ownerNames, ownerIds
+	if len(names) == 0 {
+		id, title, err := youtubeService.LookupChannel("mine")
+		if err != nil {
+			return nil, fmt.Errorf("could not look up your channel name, use --owner-name: %w", err)
+		}
+		names = []string{title}
+		if len(ids) == 0 {
+			ids = []string{id}
+		}
+	}
+	return yt.TrainClassifier(examples, names, ids)
+}
+
+// classify appends the spam score of the comment to prefix when comments are
+// being classified.
+func classify(prefix string, comment *youtube.Comment) string {
+	if classifier == nil {
+		return prefix
+	}
+	authorChannelId := ""
+	if comment.Snippet.AuthorChannelId != nil {
+		authorChannelId = comment.Snippet.AuthorChannelId.Value
+	}
+	p := classifier.SpamProbability(authorChannelId, comment.Snippet.AuthorDisplayName, comment.Snippet.TextOriginal)
+	if p >= spamThreshold {
+		return prefix + color.RedString("spam %.2f ", p)
 	}
+	return prefix + color.GreenString("ok %.2f ", p)
 }
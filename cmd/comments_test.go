@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		value string
+		ago   time.Duration
+	}{
+		{"24h", 24 * time.Hour},
+		{"90m", 90 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			before := time.Now()
+			got, err := parseSince(test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Before(before.Add(-test.ago)) || got.After(time.Now().Add(-test.ago)) {
+				t.Errorf("parseSince() = %v, want %v ago", got, test.ago)
+			}
+		})
+	}
+
+	got, err := parseSince("2021-08-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 8, 1, 0, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("parseSince() = %v, want %v", got, want)
+	}
+	if _, err := parseSince("last week"); err == nil {
+		t.Error("parseSince(\"last week\") did not fail")
+	}
+	if got, err := parseSince(""); got != nil || err != nil {
+		t.Errorf("parseSince(\"\") = %v, %v, want nil", got, err)
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+var giveawayCommand = &cobra.Command{
+	Use:   "giveaway",
+	Short: "Run giveaways described in a YAML file",
+	Long: `This command runs giveaways from a YAML spec that can be reviewed and version controlled.
+
+A spec holds the same settings as the winner flags:
+
+  videos:
+    - https://www.youtube.com/watch?v=oYBGPVwNK2c
+  # or entrantsFile: entrants.ndjson, see "yt winner --help" for its format
+  # ownerChannelId: UCxyz    # with entrantsFile, needed unless exclude.owner is false
+  count: 1                   # ignored when prizes are set
+  claimDays: 7               # claim deadline tracked with "yt giveaway winners"
+  prizes:
+    - name: Grand prize
+      count: 1
+    - name: Runner-up
+      count: 5
+  alternates: 3
+  entrantsCsv: entrants.csv
+  mode: any                  # or all
+  videoWeights:
+    - video: oYBGPVwNK2c
+      weight: 2
+  window:
+    opens: 2021-08-01 18:00
+    closes: 2021-08-08 18:00
+    timezone: America/New_York
+    lateEdits: reject        # or flag
+  entry:
+    contains: ["giveaway"]
+    matches: []
+    hashtags: ["teamblue"]
+    minMentions: 2
+    minLength: 10
+  exclude:
+    owner: true
+    moderators: moderators.txt
+    blocklist: blocklist.txt
+    recentWinnersDays: 30
+  tickets:
+    perComment: 0
+    perLike: 0
+    perReply: 0
+    member: 0
+    membersFile: members.txt
+    max: 0
+  channels:
+    minAgeDays: 30
+    minVideos: 0
+    minSubscribers: 0
+  subscribersOnly: false
+  privateSubscriptions: redraw  # or allow
+  notify:
+    enabled: true
+    template: "Congratulations {winner}! {claim}"
+    claim: "Send us a DM within 7 days to claim your prize."`,
+}
+
+func init() {
+	runCmd := &cobra.Command{
+		Use:     "run <spec.yaml>",
+		Short:   "Validate a giveaway spec and draw its winners",
+		Example: "yt giveaway run giveaways/summer.yaml",
+		Args:    cobra.ExactArgs(1),
+		Run:     giveawayRunCmd,
+	}
+	checkCmd := &cobra.Command{
+		Use:   "check <spec.yaml>",
+		Short: "Validate a giveaway spec and show its entrants without drawing",
+		Long: `This command validates a giveaway spec and shows who would enter it without drawing.
+Live specs are only validated, since their entrants are collected from the chat when they run.`,
+		Example: "yt giveaway check giveaways/summer.yaml",
+		Args:    cobra.ExactArgs(1),
+		Run:     giveawayCheckCmd,
+	}
+	giveawayCommand.AddCommand(runCmd, checkCmd)
+	rootCmd.AddCommand(giveawayCommand)
+}
+
+func giveawayRunCmd(_ *cobra.Command, args []string) {
+	spec, err := readGiveawaySpec(args[0])
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	runGiveaway(spec)
+}
+
+func giveawayCheckCmd(_ *cobra.Command, args []string) {
+	spec, err := readGiveawaySpec(args[0])
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+
+	rules, err := spec.rules()
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	if spec.Live != "" {
+		color.Green("giveaway is valid, its entrants are collected from the live chat when it runs")
+		return
+	}
+
+	tokenProvider, youtubeService := spec.service(rules)
+
+	start := time.Now()
+	err = spec.check(youtubeService, rules)
+	total := time.Since(start).Milliseconds()
+	if err != nil {
+		color.Red("yt: %v", err)
+	}
+	fmt.Printf("took %dms\n", total)
+
+	if tokenProvider != nil {
+		saveToken(tokenProvider)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
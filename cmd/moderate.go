@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	yt "github.com/amanzanero/yt/youtube"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	moderateChannel     string
+	moderateStatus      string
+	moderateInteractive bool
+)
+
+var moderateCommand = &cobra.Command{
+	Use:   "moderate [<video url>]",
+	Short: "Review comments held for review or marked as likely spam",
+	Long: `This command lists the comments held for review or marked as likely spam on a video, or with
+--channel on every video of a channel. The channel is "mine", a channel ID or a channel URL.
+
+With --interactive each comment is shown in turn to approve, reject, reject and
+ban its author, or skip. The approve, reject and ban subcommands moderate
+comments by ID without asking.`,
+	Example: `yt moderate https://www.youtube.com/watch?v=oYBGPVwNK2c
+yt moderate --channel mine --status likelySpam --interactive
+yt moderate reject Ugx1a2b3c4 Ugy5d6e7f8`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if moderateChannel != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: moderateCmd,
+}
+
+func init() {
+	localCmd := moderateCommand
+	localCmd.Flags().StringVar(&moderateChannel, "channel", "", "review comments across every video of this channel instead")
+	localCmd.Flags().StringVar(&moderateStatus, "status", "", "only review comments with this status: heldForReview or likelySpam (default both)")
+	localCmd.Flags().BoolVarP(&moderateInteractive, "interactive", "i", false, "step through the comments and moderate each one")
+
+	for _, action := range []struct {
+		name  string
+		short string
+	}{
+		{yt.Approve, "Publish comments by ID"},
+		{yt.Reject, "Reject comments by ID"},
+		{yt.Ban, "Reject comments by ID and ban their authors from the channel"},
+	} {
+		action := action
+		localCmd.AddCommand(&cobra.Command{
+			Use:   action.name + " <comment id>...",
+			Short: action.short,
+			Args:  cobra.MinimumNArgs(1),
+			Run: func(_ *cobra.Command, args []string) {
+				moderateIdsCmd(action.name, args)
+			},
+		})
+	}
+	rootCmd.AddCommand(localCmd)
+}
+
+func moderateCmd(_ *cobra.Command, args []string) {
+	statuses := []string{yt.HeldForReview, yt.LikelySpam}
+	if moderateStatus != "" {
+		statuses = []string{moderateStatus}
+	}
+
+	tokenProvider, youtubeService := newService()
+
+	start := time.Now()
+	comments := make([]*yt.CommentThread, 0)
+	for _, status := range statuses {
+		opts := yt.CommentOptions{ModerationStatus: status}
+		var threads []*yt.CommentThread
+		var err error
+		if moderateChannel != "" {
+			threads, err = youtubeService.ListChannelComments(moderateChannel, opts)
+		} else {
+			threads, err = youtubeService.ListComments(args[0], opts)
+		}
+		if err != nil {
+			color.Red("yt: %v", err)
+			os.Exit(1)
+		}
+		comments = append(comments, threads...)
+	}
+	fmt.Printf("took %dms\n", time.Since(start).Milliseconds())
+
+	if len(comments) == 0 {
+		fmt.Println("no comments to review")
+	} else if moderateInteractive {
+		stepThrough(youtubeService, comments)
+	} else {
+		for i, comment := range comments {
+			printModerated(i, comment)
+		}
+	}
+
+	saveToken(tokenProvider)
+}
+
+// printModerated prints a comment waiting for moderation with its ID, so it
+// can be passed to the moderation subcommands.
+func printModerated(i int, comment *yt.CommentThread) {
+	if moderateChannel != "" {
+		printVideoLabel(comment)
+	}
+	color.Yellow("%d: %s %s", i+1, comment.Comment.Snippet.ModerationStatus, comment.Comment.Id)
+	printComment("   ", "   ", comment.Comment)
+}
+
+// stepThrough asks what to do with each comment and does it right away, so
+// quitting keeps what was already moderated.
+func stepThrough(youtubeService *yt.Service, comments []*yt.CommentThread) {
+	actions := map[string]string{"a": yt.Approve, "r": yt.Reject, "b": yt.Ban}
+	done := make(map[string]int)
+	stdin := bufio.NewReader(os.Stdin)
+
+	for i := 0; i < len(comments); {
+		printModerated(i, comments[i])
+		fmt.Print("[a]pprove, [r]eject, [b]an, [s]kip or [q]uit? ")
+		answer, err := stdin.ReadString('\n')
+		if err != nil {
+			break
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer == "q" {
+			break
+		}
+		if answer == "s" {
+			i++
+			continue
+		}
+		action, ok := actions[answer]
+		if !ok {
+			continue
+		}
+		if err := youtubeService.Moderate([]string{comments[i].Comment.Id}, action); err != nil {
+			color.Red("yt: %v", err)
+			continue
+		}
+		done[action]++
+		i++
+	}
+	fmt.Printf("%d approved, %d rejected, %d banned\n", done[yt.Approve], done[yt.Reject], done[yt.Ban])
+}
+
+func moderateIdsCmd(action string, commentIds []string) {
+	tokenProvider, youtubeService := newService()
+
+	start := time.Now()
+	err := youtubeService.Moderate(commentIds, action)
+	total := time.Since(start).Milliseconds()
+	if err != nil {
+		color.Red("yt: %v", err)
+	} else {
+		color.Green("%s: %d comments", action, len(commentIds))
+	}
+	fmt.Printf("took %dms\n", total)
+
+	saveToken(tokenProvider)
+	if err != nil {
+		os.Exit(1)
+	}
+}
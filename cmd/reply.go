@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	yt "github.com/amanzanero/yt/youtube"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	replyFile     string
+	replyInterval time.Duration
+	replyDryRun   bool
+)
+
+// reply is a reply text for the top-level comment with CommentId.
+type reply struct {
+	CommentId string `mapstructure:"commentId"`
+	Text      string `mapstructure:"text"`
+}
+
+func init() {
+	localCmd := &cobra.Command{
+		Use:   "reply <comment id> <text>",
+		Short: "Reply to comments",
+		Long: `This command replies to a top-level comment, or with --file to many of them.
+
+The file is a CSV with commentId and text columns, or a YAML file:
+
+  replies:
+    - commentId: Ugx1a2b3c4
+      text: Thanks for watching!
+
+Replies are posted one at a time, --interval apart, and the ones that failed
+are listed at the end. Errors that would fail every reply, like an exhausted
+quota or an expired login, stop the command and the rest are listed as not
+attempted.`,
+		Example: `yt reply Ugx1a2b3c4 "Thanks for watching!"
+yt reply --file replies.csv --dry-run`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if replyFile != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
+		Run: replyCmd,
+	}
+	localCmd.Flags().StringVarP(&replyFile, "file", "f", "", "CSV or YAML file of comment IDs and reply texts")
+	localCmd.Flags().DurationVar(&replyInterval, "interval", time.Second, "time to wait between replies")
+	localCmd.Flags().BoolVar(&replyDryRun, "dry-run", false, "print the replies instead of posting them")
+	rootCmd.AddCommand(localCmd)
+}
+
+func replyCmd(_ *cobra.Command, args []string) {
+	var replies []*reply
+	if replyFile != "" {
+		var err error
+		if replies, err = readReplies(replyFile); err != nil {
+			color.Red("yt: %v", err)
+			os.Exit(1)
+		}
+	} else {
+		replies = []*reply{{CommentId: args[0], Text: args[1]}}
+	}
+
+	if replyDryRun {
+		for _, r := range replies {
+			fmt.Printf("would reply to %s: %s\n", r.CommentId, r.Text)
+		}
+		return
+	}
+
+	tokenProvider, youtubeService := newService()
+
+	start := time.Now()
+	failed := make(map[*reply]error)
+	posted := 0
+	for i, r := range replies {
+		if i > 0 {
+			time.Sleep(replyInterval)
+		}
+		if _, err := youtubeService.ReplyToComment(r.CommentId, r.Text); err != nil {
+			color.Red("yt: could not reply to %s: %v", r.CommentId, err)
+			failed[r] = err
+			if yt.IsFatal(err) {
+				// the rest would fail the same way, and count against the quota
+				break
+			}
+			continue
+		}
+		posted++
+		fmt.Printf("replied to %s\n", r.CommentId)
+	}
+	fmt.Printf("took %dms\n", time.Since(start).Milliseconds())
+
+	saveToken(tokenProvider)
+	if skipped := len(replies) - posted - len(failed); skipped > 0 {
+		color.Red("stopped after a fatal error, %d replies were not attempted:", skipped)
+		for _, r := range replies[posted+len(failed):] {
+			fmt.Printf("  %s\n", r.CommentId)
+		}
+	}
+	if len(failed) > 0 {
+		color.Red("%d of %d replies failed:", len(failed), len(replies))
+		for _, r := range replies {
+			if err, ok := failed[r]; ok {
+				fmt.Printf("  %s: %v\n", r.CommentId, err)
+			}
+		}
+		os.Exit(1)
+	}
+}
+
+// readReplies reads a CSV file with commentId and text columns, or a YAML
+// file with a list of replies.
+func readReplies(path string) ([]*reply, error) {
+	var replies []*reply
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		replies, err = readRepliesCsv(path)
+	case ".yaml", ".yml":
+		v := viper.New()
+		v.SetConfigFile(path)
+		v.SetConfigType("yaml")
+		if err = v.ReadInConfig(); err == nil {
+			err = v.UnmarshalKey("replies", &replies)
+		}
+	default:
+		return nil, fmt.Errorf("%s: replies must be a .csv or .yaml file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if len(replies) == 0 {
+		return nil, fmt.Errorf("%s: no replies", path)
+	}
+	for i, r := range replies {
+		if r.CommentId == "" || strings.TrimSpace(r.Text) == "" {
+			return nil, fmt.Errorf("%s: reply %d needs a commentId and text", path, i+1)
+		}
+	}
+	return replies, nil
+}
+
+func readRepliesCsv(path string) ([]*reply, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	commentColumn, textColumn := -1, -1
+	for i, name := range header {
+		switch strings.TrimSpace(name) {
+		case "commentId":
+			commentColumn = i
+		case "text":
+			textColumn = i
+		}
+	}
+	if commentColumn < 0 || textColumn < 0 {
+		return nil, errors.New("missing commentId or text column")
+	}
+
+	replies := make([]*reply, 0)
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return replies, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, &reply{CommentId: row[commentColumn], Text: row[textColumn]})
+	}
+}
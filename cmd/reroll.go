@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/amanzanero/yt/youtube"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var rerollReason string
+
+func init() {
+	localCmd := &cobra.Command{
+		Use:   "reroll <record> <winner>...",
+		Short: "Replace winners with the next alternates of a saved draw",
+		Long: `This command disqualifies winners of a saved draw and promotes the next alternates in their place.
+The draw is a record ID or path and winners are named by channel ID, comment ID or display name. Either every
+winner is rerolled or, when a name matches no winner or too few alternates are left, none is. The youtube API is not called.`,
+		Example: `yt winner reroll ~/.yt/draws/20210801-150405-a1b2c3.json UCxyz --reason "prize not claimed within 7 days"`,
+		Args:    cobra.MinimumNArgs(2),
+		Run:     rerollCmd,
+	}
+	localCmd.Flags().StringVar(&rerollReason, "reason", "", "why the winners are disqualified (required)")
+	_ = localCmd.MarkFlagRequired("reason")
+	winnerCommand.AddCommand(localCmd)
+}
+
+func rerollCmd(_ *cobra.Command, args []string) {
+	path := youtube.FindDrawRecord(args[0])
+	record, err := youtube.ReadDrawRecord(path)
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+
+	rerolls, err := record.RerollWinners(args[1:], rerollReason)
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	for _, reroll := range rerolls {
+		color.Yellow("Disqualified: \"%s\" (%s)", reroll.Disqualified.AuthorDisplayName, reroll.Reason)
+		color.Blue("Promoted: \"%s\"", reroll.Promoted.AuthorDisplayName)
+	}
+
+	if _, err := record.Save(path); err != nil {
+		color.Red("yt: could not save draw record: %v", err)
+		os.Exit(1)
+	}
+	if err := youtube.AppendLedgerRerolls(record, rerolls); err != nil {
+		color.Red("yt: could not update winners ledger: %v", err)
+		os.Exit(1)
+	}
+	for i, winner := range record.Winners {
+		fmt.Printf("Winner #%d: \"%s\"\n", i+1, winner.AuthorDisplayName)
+	}
+	fmt.Printf("%d alternates left\n", len(record.Alternates))
+}
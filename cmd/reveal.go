@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/amanzanero/yt/youtube"
+	"github.com/fatih/color"
+	"html/template"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// revealShow replays an already finished draw for an audience. The names are
+// only cycled for effect: the winners are decided before the show starts.
+type revealShow struct {
+	Names   []string
+	Labels  []string
+	Winners []string
+
+	mu      sync.Mutex
+	started bool
+}
+
+func newRevealShow(pool []*youtube.Candidate, winners []*youtube.Winner) *revealShow {
+	show := &revealShow{
+		Names:   make([]string, 0, len(pool)),
+		Labels:  make([]string, 0, len(winners)),
+		Winners: make([]string, 0, len(winners)),
+	}
+	for _, candidate := range pool {
+		show.Names = append(show.Names, candidate.AuthorDisplayName)
+	}
+	for i, winner := range winners {
+		label := fmt.Sprintf("Winner #%d", i+1)
+		if winner.Prize != "" {
+			label = winner.Prize
+		}
+		show.Labels = append(show.Labels, label)
+		show.Winners = append(show.Winners, winner.AuthorDisplayName)
+	}
+	return show
+}
+
+// run reveals the winners in the terminal, and on a local web page when addr
+// is set, after waiting for enter.
+func (show *revealShow) run(addr string) error {
+	if addr != "" {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("can't listen on %s: %w", addr, err)
+		}
+		server := &http.Server{Handler: show.handler()}
+		go func() {
+			if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				color.Red("yt: %v", err)
+			}
+		}()
+		defer server.Close()
+		fmt.Printf("reveal page: http://%s/\n", l.Addr())
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+	fmt.Print("press enter to start the reveal")
+	_, _ = stdin.ReadString('\n')
+
+	show.mu.Lock()
+	show.started = true
+	show.mu.Unlock()
+	show.terminal()
+
+	if addr != "" {
+		fmt.Print("press enter to stop the reveal page")
+		_, _ = stdin.ReadString('\n')
+	}
+	return nil
+}
+
+// terminal cycles through the entrant names, slowing down, before settling on
+// each winner.
+func (show *revealShow) terminal() {
+	names := show.Names
+	if len(names) == 0 {
+		names = show.Winners
+	}
+	cycle := color.New(color.FgHiBlack).SprintFunc()
+	for i, winner := range show.Winners {
+		delay := 40 * time.Millisecond
+		for delay < 600*time.Millisecond {
+			fmt.Printf("\r\033[K%s", cycle(names[rand.Intn(len(names))]))
+			time.Sleep(delay)
+			delay = delay * 23 / 20
+		}
+		fmt.Print("\r\033[K")
+		color.New(color.FgBlue, color.Bold).Printf("%s: \"%s\"\n", show.Labels[i], winner)
+		time.Sleep(time.Second)
+	}
+}
+
+func (show *revealShow) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = revealPage.Execute(w, nil)
+	})
+	mux.HandleFunc("/state", func(w http.ResponseWriter, r *http.Request) {
+		show.mu.Lock()
+		defer show.mu.Unlock()
+		state := struct {
+			Started bool     `json:"started"`
+			Names   []string `json:"names"`
+			Labels  []string `json:"labels"`
+			Winners []string `json:"winners"`
+		}{Started: show.started, Names: show.Names, Labels: show.Labels}
+		// the page is polled before the reveal, it must not learn the winners early
+		if show.started {
+			state.Winners = show.Winners
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(state)
+	})
+	return mux
+}
+
+// revealPage runs the same reveal as the terminal. It is meant to be added to
+// streaming software as a browser source, so the background is transparent.
+var revealPage = template.Must(template.New("reveal").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>yt winner reveal</title>
+<style>
+  body { background: transparent; margin: 0; font-family: sans-serif; color: #fff; }
+  #stage { display: flex; flex-direction: column; align-items: center; justify-content: center; height: 100vh; }
+  #label { font-size: 4vh; opacity: 0.8; }
+  #name { font-size: 10vh; font-weight: bold; text-shadow: 0 0 2vh #000; }
+  #name.cycling { opacity: 0.6; }
+  #name.winner { color: #ffd700; }
+</style>
+</head>
+<body>
+<div id="stage"><div id="label"></div><div id="name"></div></div>
+<script>
+const label = document.getElementById("label");
+const name = document.getElementById("name");
+const sleep = (ms) => new Promise((resolve) => setTimeout(resolve, ms));
+
+async function reveal(show) {
+  const names = show.names.length > 0 ? show.names : show.winners;
+  for (let i = 0; i < show.winners.length; i++) {
+    label.textContent = show.labels[i];
+    name.className = "cycling";
+    for (let delay = 40; delay < 600; delay = delay * 23 / 20) {
+      name.textContent = names[Math.floor(Math.random() * names.length)];
+      await sleep(delay);
+    }
+    name.className = "winner";
+    name.textContent = show.winners[i];
+    await sleep(3000);
+  }
+}
+
+async function waitForStart() {
+  for (;;) {
+    const show = await (await fetch("/state")).json();
+    if (show.started) {
+      return reveal(show);
+    }
+    await sleep(500);
+  }
+}
+
+waitForStart();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/amanzanero/yt/youtube"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRevealState(t *testing.T) {
+	pool := []*youtube.Candidate{{AuthorDisplayName: "one"}, {AuthorDisplayName: "two"}}
+	show := newRevealShow(pool, []*youtube.Winner{{AuthorDisplayName: "two", Prize: "Grand prize"}})
+	handler := show.handler()
+
+	tests := []struct {
+		started bool
+		winners []string
+	}{
+		{false, nil},
+		{true, []string{"two"}},
+	}
+	for _, test := range tests {
+		show.started = test.started
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/state", nil))
+
+		var state struct {
+			Started bool     `json:"started"`
+			Names   []string `json:"names"`
+			Labels  []string `json:"labels"`
+			Winners []string `json:"winners"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&state); err != nil {
+			t.Fatal(err)
+		}
+		if state.Started != test.started || len(state.Names) != 2 || len(state.Labels) != 1 {
+			t.Errorf("state = %+v", state)
+		}
+		if len(state.Winners) != len(test.winners) || (len(test.winners) > 0 && state.Winners[0] != test.winners[0]) {
+			t.Errorf("started %v: winners = %v, want %v", test.started, state.Winners, test.winners)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	yt "github.com/amanzanero/yt/youtube"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/youtube/v3"
+	"os"
 )
 
 var (
@@ -36,3 +39,31 @@ func Execute() error {
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 }
+
+// newService authorizes the user and returns the youtube service along with
+// the token source, which should be saved with saveToken when done.
+func newService() (oauth2.TokenSource, *yt.Service) {
+	tokenProvider, err := yt.NewTokenProvider(config)
+	cobra.CheckErr(err)
+
+	youtubeService, err := yt.New(yt.WithTokenSource(tokenProvider))
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	return tokenProvider, youtubeService
+}
+
+// saveToken stores the token in case it refreshed.
+func saveToken(tokenProvider oauth2.TokenSource) {
+	token, err := tokenProvider.Token()
+	if err != nil {
+		color.Red("yt: could not save token")
+		os.Exit(1)
+	}
+	err = yt.WriteToken(token)
+	if err != nil {
+		color.Red("yt: could not save token")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,561 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/amanzanero/yt/youtube"
+	"github.com/fatih/color"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// giveawaySpec is everything needed to run a giveaway. The winner command
+// fills it from flags and the giveaway command from a YAML file.
+type giveawaySpec struct {
+	Videos []string `mapstructure:"videos"`
+	Live   string   `mapstructure:"live"`
+	Count  int      `mapstructure:"count"`
+	// EntrantsFile draws from an entrant export instead of the API. Owner is
+	// the channel excluded as the owner, since an export does not say.
+	EntrantsFile string `mapstructure:"entrantsFile"`
+	Owner        string `mapstructure:"ownerChannelId"`
+	// Prizes replace Count when set. PrizeFlags holds them as NAME=COUNT.
+	Prizes     []youtube.Prize `mapstructure:"prizes"`
+	PrizeFlags []string        `mapstructure:"-"`
+	Alternates int             `mapstructure:"alternates"`
+	Seed       string          `mapstructure:"seed"`
+	Commit     bool            `mapstructure:"commit"`
+	Record     string          `mapstructure:"record"`
+	ClaimDays  int             `mapstructure:"claimDays"`
+	Snapshot   string          `mapstructure:"entrantsCsv"`
+
+	Mode string `mapstructure:"mode"`
+	// VideoWeights is set by flags. YAML uses Weights, since viper lower cases
+	// map keys and video IDs are case sensitive.
+	VideoWeights map[string]int `mapstructure:"-"`
+	Weights      []videoWeight  `mapstructure:"videoWeights"`
+
+	Window   windowSpec           `mapstructure:"window"`
+	Entry    youtube.EntryRules   `mapstructure:"entry"`
+	Exclude  excludeSpec          `mapstructure:"exclude"`
+	Tickets  ticketSpec           `mapstructure:"tickets"`
+	Channels youtube.ChannelRules `mapstructure:"channels"`
+	Odds     bool                 `mapstructure:"odds"`
+
+	SubscribersOnly      bool   `mapstructure:"subscribersOnly"`
+	PrivateSubscriptions string `mapstructure:"privateSubscriptions"`
+
+	Notify notifySpec `mapstructure:"notify"`
+	Reveal revealSpec `mapstructure:"reveal"`
+}
+
+type videoWeight struct {
+	Video  string `mapstructure:"video"`
+	Weight int    `mapstructure:"weight"`
+}
+
+type windowSpec struct {
+	Opens     string `mapstructure:"opens"`
+	Closes    string `mapstructure:"closes"`
+	Timezone  string `mapstructure:"timezone"`
+	LateEdits string `mapstructure:"lateEdits"`
+}
+
+type excludeSpec struct {
+	Owner         bool   `mapstructure:"owner"`
+	Moderators    string `mapstructure:"moderators"`
+	Blocklist     string `mapstructure:"blocklist"`
+	RecentWinners int    `mapstructure:"recentWinnersDays"`
+}
+
+type ticketSpec struct {
+	youtube.TicketRules `mapstructure:",squash"`
+	MembersFile         string `mapstructure:"membersFile"`
+}
+
+type notifySpec struct {
+	youtube.Notification `mapstructure:",squash"`
+	Enabled              bool `mapstructure:"enabled"`
+	DryRun               bool `mapstructure:"dryRun"`
+}
+
+type revealSpec struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Addr    string `mapstructure:"addr"`
+}
+
+// readGiveawaySpec loads a giveaway spec from a YAML file. Anything the file
+// leaves out gets the same default as the matching winner flag.
+func readGiveawaySpec(path string) (*giveawaySpec, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	v.SetDefault("count", 1)
+	v.SetDefault("alternates", 3)
+	v.SetDefault("mode", youtube.AnyVideo)
+	v.SetDefault("window.lateEdits", youtube.RejectLateEdits)
+	v.SetDefault("exclude.owner", true)
+	v.SetDefault("privateSubscriptions", youtube.RedrawPrivate)
+	v.SetDefault("notify.template", youtube.DefaultNotification)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	spec := new(giveawaySpec)
+	if err := v.UnmarshalExact(spec); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// rules resolves the spec into draw rules, reading every referenced file.
+func (g *giveawaySpec) rules() (youtube.Rules, error) {
+	prizes, err := g.prizes()
+	if err != nil {
+		return youtube.Rules{}, err
+	}
+	rules := youtube.Rules{
+		Count:                g.Count,
+		Prizes:               prizes,
+		Alternates:           g.Alternates,
+		Entry:                g.Entry,
+		Mode:                 g.Mode,
+		Tickets:              g.Tickets.TicketRules,
+		ExcludeOwner:         g.Exclude.Owner,
+		Channels:             g.Channels,
+		SubscribersOnly:      g.SubscribersOnly,
+		PrivateSubscriptions: g.PrivateSubscriptions,
+	}
+	if len(g.VideoWeights) > 0 || len(g.Weights) > 0 {
+		rules.VideoWeights = make(map[string]int)
+		for video, weight := range g.VideoWeights {
+			rules.VideoWeights[video] = weight
+		}
+		for _, weight := range g.Weights {
+			rules.VideoWeights[weight.Video] = weight.Weight
+		}
+	}
+
+	if len(prizes) > 0 {
+		rules.Count = 0
+		for _, prize := range prizes {
+			rules.Count += prize.Count
+		}
+	}
+
+	if g.Tickets.MembersFile != "" {
+		members, err := youtube.ReadChannelList(g.Tickets.MembersFile)
+		if err != nil {
+			return rules, err
+		}
+		rules.Tickets.Members = append(rules.Tickets.Members, members...)
+	}
+	if rules.Exclusions, err = g.exclusions(); err != nil {
+		return rules, err
+	}
+	rules.Entry.LateEdits = g.Window.LateEdits
+	if rules.Entry.Opens, err = parseTime(g.Window.Opens, g.Window.Timezone); err != nil {
+		return rules, fmt.Errorf("entry window opens: %w", err)
+	}
+	if rules.Entry.Closes, err = parseTime(g.Window.Closes, g.Window.Timezone); err != nil {
+		return rules, fmt.Errorf("entry window closes: %w", err)
+	}
+	if err := rules.Validate(); err != nil {
+		return rules, err
+	}
+
+	if g.Reveal.Addr != "" && !g.Reveal.Enabled {
+		return rules, errors.New("a reveal address needs the reveal enabled")
+	}
+	if g.ClaimDays < 0 {
+		return rules, errors.New("claim days cannot be negative")
+	}
+	if g.Notify.DryRun && !g.Notify.Enabled {
+		return rules, errors.New("a dry run previews the winner notifications and needs them enabled")
+	}
+	if g.EntrantsFile != "" {
+		if len(g.Videos) > 0 || g.Live != "" {
+			return rules, errors.New("an entrants file cannot be combined with videos or a live draw")
+		}
+		if rules.ExcludeOwner && g.Owner == "" {
+			return rules, errors.New("excluding the owner from an entrants file needs the owner channel ID, or turn the exclusion off")
+		}
+	} else if g.Live != "" {
+		if len(g.Videos) > 0 {
+			return rules, errors.New("a live draw cannot also draw from videos")
+		}
+		if len(rules.Entry.Contains) == 0 && len(rules.Entry.Matches) == 0 {
+			return rules, errors.New("live draws need an entry keyword the chat messages contain or match")
+		}
+		if rules.Entry.Opens == nil || rules.Entry.Closes == nil {
+			return rules, errors.New("live draws need the entry window to open and close")
+		}
+		if g.Notify.Enabled {
+			return rules, errors.New("live chat messages cannot be replied to, announce live winners in the chat")
+		}
+	} else if len(g.Videos) == 0 {
+		return rules, errors.New("no videos to draw from")
+	}
+	return rules, nil
+}
+
+// prizes returns the prize tiers of the spec followed by the ones from flags.
+func (g *giveawaySpec) prizes() ([]youtube.Prize, error) {
+	prizes := append([]youtube.Prize{}, g.Prizes...)
+	for _, flag := range g.PrizeFlags {
+		i := strings.LastIndex(flag, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("prize %q must look like NAME=COUNT", flag)
+		}
+		count, err := strconv.Atoi(flag[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("prize %q must look like NAME=COUNT", flag)
+		}
+		prizes = append(prizes, youtube.Prize{Name: flag[:i], Count: count})
+	}
+	return prizes, nil
+}
+
+// exclusions collects the channels kept out of the draw by the moderators
+// file, the blocklist and the winners ledger.
+func (g *giveawaySpec) exclusions() ([]youtube.Exclusion, error) {
+	exclusions := make([]youtube.Exclusion, 0)
+	add := func(channels []string, reason string) {
+		for _, channel := range channels {
+			exclusions = append(exclusions, youtube.Exclusion{AuthorChannelId: channel, Reason: reason})
+		}
+	}
+
+	if g.Exclude.Moderators != "" {
+		moderators, err := youtube.ReadChannelList(g.Exclude.Moderators)
+		if err != nil {
+			return nil, err
+		}
+		add(moderators, "moderator")
+	}
+	if g.Exclude.Blocklist != "" {
+		blocked, err := youtube.ReadChannelList(g.Exclude.Blocklist)
+		if err != nil {
+			return nil, err
+		}
+		add(blocked, "blocklist")
+	}
+	if g.Exclude.RecentWinners > 0 {
+		since := time.Now().AddDate(0, 0, -g.Exclude.RecentWinners)
+		recent, err := youtube.RecentWinners(since)
+		if err != nil {
+			return nil, err
+		}
+		add(recent, fmt.Sprintf("won in the last %d days", g.Exclude.RecentWinners))
+	}
+	return exclusions, nil
+}
+
+// seed returns the spec's seed or a new one. With commit set it prints the
+// commitment and waits for confirmation before the draw goes ahead.
+func (g *giveawaySpec) seed() (string, error) {
+	seed := g.Seed
+	if seed == "" {
+		var err error
+		if seed, err = youtube.NewSeed(); err != nil {
+			return "", err
+		}
+	}
+	if g.Commit {
+		color.Yellow("commitment: %s", youtube.Commitment(seed))
+		fmt.Print("publish the commitment, then press enter to collect the entrants")
+		_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+	}
+	return seed, nil
+}
+
+// offline reports whether the giveaway can run without the youtube API: its
+// entrants come from a file and no rule needs to look anything up.
+func (g *giveawaySpec) offline(rules youtube.Rules) bool {
+	notify := g.Notify.Enabled && !g.Notify.DryRun
+	return g.EntrantsFile != "" && !rules.SubscribersOnly && !rules.Channels.Enabled() && !notify
+}
+
+// service returns the youtube service, or nils when the giveaway runs offline.
+func (g *giveawaySpec) service(rules youtube.Rules) (oauth2.TokenSource, *youtube.Service) {
+	if g.offline(rules) {
+		return nil, nil
+	}
+	return newService()
+}
+
+// prepare collects the entrants of the giveaway without drawing.
+// youtubeService is nil for offline giveaways.
+func (g *giveawaySpec) prepare(youtubeService *youtube.Service, rules youtube.Rules, seed string) (*youtube.DrawRecord, []*youtube.Candidate, error) {
+	var record *youtube.DrawRecord
+	var err error
+	switch {
+	case g.EntrantsFile != "":
+		record, err = g.prepareFile(youtubeService, rules, seed)
+	case g.Live != "":
+		record, err = youtubeService.PrepareLiveDraw(g.Live, rules, seed)
+	default:
+		record, err = youtubeService.PrepareDraw(g.Videos, rules, seed)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	pool, report, err := record.Pool()
+	if err != nil {
+		return nil, nil, err
+	}
+	record.Report = report
+	printDrawReport(report)
+	// odds are shown whenever anyone holds more than one ticket
+	if g.Odds || report.Tickets > int64(report.Entrants) {
+		printOdds(pool, report.Tickets)
+	}
+	if g.Snapshot != "" {
+		if record.SnapshotDigest, err = record.WriteEntrantSnapshot(g.Snapshot, pool); err != nil {
+			return nil, nil, fmt.Errorf("could not write entrant snapshot: %w", err)
+		}
+		color.Yellow("entrant snapshot: %s", g.Snapshot)
+		color.Yellow("entrant snapshot sha256: %s", record.SnapshotDigest)
+	} else if g.Commit {
+		if record.SnapshotDigest, err = record.EntrantSnapshotDigest(pool); err != nil {
+			return nil, nil, err
+		}
+		color.Yellow("entrant snapshot sha256: %s", record.SnapshotDigest)
+	}
+	return record, pool, nil
+}
+
+// beacon asks for a public random value announced after the snapshot digest
+// was published, such as a drand round or a block hash, to mix into the seed.
+func (g *giveawaySpec) beacon() string {
+	fmt.Print("publish the entrant snapshot sha256, then enter a beacon value announced after it: ")
+	beacon, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	beacon = strings.TrimSpace(beacon)
+	if beacon == "" {
+		color.Yellow("no beacon: whoever knows the seed could have known the winners before the draw")
+	}
+	return beacon
+}
+
+func (g *giveawaySpec) prepareFile(youtubeService *youtube.Service, rules youtube.Rules, seed string) (*youtube.DrawRecord, error) {
+	entrants, err := youtube.ReadEntrants(g.EntrantsFile)
+	if err != nil {
+		return nil, err
+	}
+	record := youtube.NewDrawRecord(youtube.EntrantVideos(entrants), entrants, rules, seed)
+	record.ChannelId = g.Owner
+	if youtubeService != nil {
+		return record, youtubeService.ScreenChannels(record)
+	}
+	return record, nil
+}
+
+// check prints who would enter the giveaway.
+func (g *giveawaySpec) check(youtubeService *youtube.Service, rules youtube.Rules) error {
+	record, _, err := g.prepare(youtubeService, rules, "")
+	if err != nil {
+		return err
+	}
+	if record.Report.Entrants < rules.Count {
+		return fmt.Errorf("%w: %d unique entrants, %d winners", youtube.NotEnoughEntrantsErr, record.Report.Entrants, rules.Count)
+	}
+	color.Green("giveaway is ready to draw")
+	return nil
+}
+
+// draw runs the giveaway: it collects the entrants, draws, saves the record,
+// reveals and announces the winners and notifies them when asked to.
+func (g *giveawaySpec) draw(youtubeService *youtube.Service, rules youtube.Rules, seed string) error {
+	record, pool, err := g.prepare(youtubeService, rules, seed)
+	if err != nil {
+		return err
+	}
+	if g.Commit {
+		record.Beacon = g.beacon()
+	} else if g.Snapshot != "" {
+		fmt.Print("publish the entrant snapshot and its sha256, then press enter to draw")
+		_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+	}
+
+	if youtubeService != nil {
+		err = youtubeService.RunDraw(record)
+	} else {
+		err = record.Run(nil)
+	}
+	for _, redraw := range record.Redraws {
+		color.Yellow("Redraw: \"%s\" (%s)", redraw.AuthorDisplayName, redraw.Reason)
+	}
+	if err != nil {
+		return err
+	}
+
+	if g.Notify.Enabled && g.Notify.DryRun {
+		// a dry run previews the winners and replies without keeping anything
+		printWinners(record)
+		for i, alternate := range record.Alternates {
+			fmt.Printf("Alternate #%d: \"%s\"\n", i+1, alternate.AuthorDisplayName)
+		}
+		for _, winner := range record.Winners {
+			fmt.Printf("would reply to %s: %s\n", winner.CommentId, g.Notify.Text(winner))
+		}
+		color.Yellow("dry run: the draw record and the winners ledger were not saved")
+		return nil
+	}
+
+	record.ClaimDays = g.ClaimDays
+	// the record is saved before any reveal, so the winners are fixed
+	path, err := record.Create(g.Record)
+	if err != nil {
+		return fmt.Errorf("could not save draw record: %w", err)
+	}
+	if err := youtube.AppendLedger(record, record.Winners); err != nil {
+		return fmt.Errorf("could not update winners ledger: %w", err)
+	}
+
+	// the reveal prints the winners itself
+	if g.Reveal.Enabled {
+		if err := newRevealShow(pool, record.Winners).run(g.Reveal.Addr); err != nil {
+			return err
+		}
+	} else {
+		printWinners(record)
+	}
+	for i, alternate := range record.Alternates {
+		fmt.Printf("Alternate #%d: \"%s\"\n", i+1, alternate.AuthorDisplayName)
+	}
+	fmt.Printf("seed: %s\n", record.Seed)
+	if record.Beacon != "" {
+		fmt.Printf("beacon: %s\n", record.Beacon)
+	}
+	fmt.Printf("draw record: %s\n", path)
+
+	if g.Notify.Enabled {
+		return g.notify(youtubeService, record.Winners)
+	}
+	return nil
+}
+
+// notify replies to every winning comment.
+func (g *giveawaySpec) notify(youtubeService *youtube.Service, winners []*youtube.Winner) error {
+	failed := 0
+	for _, winner := range winners {
+		if _, err := youtubeService.ReplyToComment(winner.CommentId, g.Notify.Text(winner)); err != nil {
+			color.Red("yt: could not notify \"%s\": %v", winner.AuthorDisplayName, err)
+			failed++
+			continue
+		}
+		fmt.Printf("notified \"%s\"\n", winner.AuthorDisplayName)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d winners were not notified", failed, len(winners))
+	}
+	return nil
+}
+
+// runGiveaway validates the spec, draws and saves the refreshed token.
+func runGiveaway(g *giveawaySpec) {
+	rules, err := g.rules()
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	seed, err := g.seed()
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+
+	tokenProvider, youtubeService := g.service(rules)
+
+	start := time.Now()
+	err = g.draw(youtubeService, rules, seed)
+	total := time.Since(start).Milliseconds()
+	if err != nil {
+		color.Red("yt: %v", err)
+	}
+	fmt.Printf("took %dms\n", total)
+
+	if tokenProvider != nil {
+		saveToken(tokenProvider)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// printWinners prints the winners, grouped by prize when the draw has prizes.
+func printWinners(record *youtube.DrawRecord) {
+	printWinner := func(label string, winner *youtube.Winner) {
+		color.Blue("%s: \"%s\"\n", label, winner.AuthorDisplayName)
+		for _, rule := range record.Report.Excluded {
+			if containsString(rule.Flagged, winner.CommentId) {
+				color.Yellow("  flagged: %s", rule.Rule)
+			}
+		}
+	}
+
+	if len(record.Rules.Prizes) == 0 {
+		for i, winner := range record.Winners {
+			printWinner(fmt.Sprintf("Winner #%d", i+1), winner)
+		}
+		return
+	}
+	for _, prize := range record.Rules.Prizes {
+		fmt.Printf("%s:\n", prize.Name)
+		n := 0
+		for _, winner := range record.Winners {
+			if winner.Prize == prize.Name {
+				n++
+				printWinner(fmt.Sprintf("  #%d", n), winner)
+			}
+		}
+	}
+}
+
+// printOdds prints every candidate's chance of being picked first, most
+// tickets first.
+func printOdds(pool []*youtube.Candidate, tickets int64) {
+	sorted := make([]*youtube.Candidate, len(pool))
+	copy(sorted, pool)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Tickets > sorted[j].Tickets
+	})
+	fmt.Println("odds of being drawn first:")
+	for _, candidate := range sorted {
+		fmt.Printf("  %6.2f%%  %4d tickets  %s\n",
+			100*float64(candidate.Tickets)/float64(tickets), candidate.Tickets, candidate.AuthorDisplayName)
+	}
+}
+
+func printDrawReport(report youtube.DrawReport) {
+	fmt.Printf("%d comments\n", report.Comments)
+	for _, rule := range report.Excluded {
+		if len(rule.Flagged) > 0 {
+			fmt.Printf("  %d flagged by rule: %s\n", len(rule.Flagged), rule.Rule)
+			continue
+		}
+		fmt.Printf("  %d comments from %d people excluded by rule: %s\n", rule.Excluded, rule.People, rule.Rule)
+	}
+	if len(report.Excluded) > 0 {
+		fmt.Printf("%d comments met every entry rule\n", report.Eligible)
+	}
+	if report.MissingVideos > 0 {
+		fmt.Printf("%d people excluded for not commenting on every video\n", report.MissingVideos)
+	}
+	fmt.Printf("%d unique entrants holding %d tickets (%d duplicate comments ignored)\n", report.Entrants, report.Tickets, report.Duplicates)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
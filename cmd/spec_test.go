@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/amanzanero/yt/youtube"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSpec writes a spec and the files it refers to into a temporary
+// directory and returns the spec path. {dir} in the spec is that directory.
+func writeSpec(t *testing.T, spec string) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"spec.yaml":      strings.ReplaceAll(spec, "{dir}", dir),
+		"members.txt":    "UCmember1\nUCmember2\n",
+		"moderators.txt": "UCmod\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "spec.yaml")
+}
+
+func TestReadGiveawaySpecDefaults(t *testing.T) {
+	spec, err := readGiveawaySpec(writeSpec(t, `
+videos:
+  - https://www.youtube.com/watch?v=oYBGPVwNK2c
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := spec.rules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.Count != 1 || rules.Alternates != 3 {
+		t.Errorf("count, alternates = %d, %d, want 1, 3", rules.Count, rules.Alternates)
+	}
+	if rules.Mode != youtube.AnyVideo || rules.Entry.LateEdits != youtube.RejectLateEdits {
+		t.Errorf("mode, late edits = %q, %q, want %q, %q", rules.Mode, rules.Entry.LateEdits, youtube.AnyVideo, youtube.RejectLateEdits)
+	}
+	if !rules.ExcludeOwner || rules.PrivateSubscriptions != youtube.RedrawPrivate {
+		t.Errorf("exclude owner, private subscriptions = %v, %q, want true, %q", rules.ExcludeOwner, rules.PrivateSubscriptions, youtube.RedrawPrivate)
+	}
+	if spec.Notify.Template != youtube.DefaultNotification {
+		t.Errorf("notify template = %q, want the default", spec.Notify.Template)
+	}
+}
+
+func TestReadGiveawaySpec(t *testing.T) {
+	spec, err := readGiveawaySpec(writeSpec(t, `
+videos:
+  - https://www.youtube.com/watch?v=oYBGPVwNK2c
+  - https://www.youtube.com/watch?v=BIk1zUy8ehU
+prizes:
+  - name: Grand prize
+    count: 1
+  - name: Runner-up
+    count: 2
+alternates: 1
+mode: all
+videoWeights:
+  - video: oYBGPVwNK2c
+    weight: 2
+window:
+  opens: 2021-08-01 18:00
+  closes: 2021-08-08 18:00
+  timezone: UTC
+  lateEdits: flag
+entry:
+  contains: ["giveaway"]
+  minMentions: 2
+exclude:
+  owner: false
+  moderators: {dir}/moderators.txt
+tickets:
+  perLike: 1
+  member: 5
+  membersFile: {dir}/members.txt
+  max: 10
+notify:
+  enabled: true
+  claim: DM us
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := spec.rules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.Count != 3 || len(rules.Prizes) != 2 || rules.Prizes[1].Name != "Runner-up" {
+		t.Errorf("count, prizes = %d, %v, want 3 from two tiers", rules.Count, rules.Prizes)
+	}
+	if rules.Alternates != 1 || rules.Mode != youtube.AllVideos {
+		t.Errorf("alternates, mode = %d, %q, want 1, %q", rules.Alternates, rules.Mode, youtube.AllVideos)
+	}
+	// video IDs are case sensitive, viper must not have lower cased them
+	if fmt.Sprint(rules.VideoWeights) != "map[oYBGPVwNK2c:2]" {
+		t.Errorf("video weights = %v, want map[oYBGPVwNK2c:2]", rules.VideoWeights)
+	}
+	opens := time.Date(2021, 8, 1, 18, 0, 0, 0, time.UTC)
+	closes := time.Date(2021, 8, 8, 18, 0, 0, 0, time.UTC)
+	if rules.Entry.Opens == nil || !rules.Entry.Opens.Equal(opens) || rules.Entry.Closes == nil || !rules.Entry.Closes.Equal(closes) {
+		t.Errorf("window = %v to %v, want %v to %v", rules.Entry.Opens, rules.Entry.Closes, opens, closes)
+	}
+	if rules.Entry.LateEdits != youtube.FlagLateEdits || fmt.Sprint(rules.Entry.Contains) != "[giveaway]" || rules.Entry.MinMentions != 2 {
+		t.Errorf("entry = %+v", rules.Entry)
+	}
+	if rules.ExcludeOwner {
+		t.Error("exclude owner = true, want false")
+	}
+	if len(rules.Exclusions) != 1 || rules.Exclusions[0].AuthorChannelId != "UCmod" {
+		t.Errorf("exclusions = %v, want UCmod", rules.Exclusions)
+	}
+	if rules.Tickets.PerLike != 1 || rules.Tickets.Member != 5 || rules.Tickets.Max != 10 || fmt.Sprint(rules.Tickets.Members) != "[UCmember1 UCmember2]" {
+		t.Errorf("tickets = %+v", rules.Tickets)
+	}
+	if !spec.Notify.Enabled || spec.Notify.Claim != "DM us" || spec.Notify.Template != youtube.DefaultNotification {
+		t.Errorf("notify = %+v", spec.Notify)
+	}
+}
+
+func TestReadGiveawaySpecErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{"unknown key", "videos: [v]\nwinners: 2", "decoding"},
+		{"no videos", "count: 1", "no videos"},
+		{"bad count", "videos: [v]\ncount: 0", "winner"},
+		{"missing file", "videos: [v]\nexclude:\n  blocklist: missing.txt", "missing.txt"},
+		{"bad window", "videos: [v]\nwindow:\n  opens: tomorrow", "opens"},
+		{"entrants file and videos", "videos: [v]\nentrantsFile: entrants.csv\nownerChannelId: UCowner", "combined"},
+		{"entrants file without owner", "entrantsFile: entrants.csv", "owner channel ID"},
+		{"live and videos", "videos: [v]\nlive: l\nentry:\n  contains: [enter]\nwindow:\n  opens: 2021-08-01\n  closes: 2021-08-02", "also draw from videos"},
+		{"live without keyword", "live: l\nwindow:\n  opens: 2021-08-01\n  closes: 2021-08-02", "keyword"},
+		{"live without window", "live: l\nentry:\n  contains: [enter]", "window"},
+		{"live notify", "live: l\nentry:\n  contains: [enter]\nwindow:\n  opens: 2021-08-01\n  closes: 2021-08-02\nnotify:\n  enabled: true", "replied to"},
+		{"dry run without notify", "videos: [v]\nnotify:\n  dryRun: true", "dry run"},
+		{"reveal address", "videos: [v]\nreveal:\n  addr: localhost:8091", "reveal"},
+		{"negative claim days", "videos: [v]\nclaimDays: -1", "claim days"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := readGiveawaySpec(writeSpec(t, test.spec))
+			if err == nil {
+				_, err = spec.rules()
+			}
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error = %v, want one about %q", err, test.want)
+			}
+		})
+	}
+}
+
+// captureStdout returns what f prints.
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	f()
+	w.Close()
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestPrintOdds(t *testing.T) {
+	pool := []*youtube.Candidate{
+		{AuthorDisplayName: "one", Tickets: 1},
+		{AuthorDisplayName: "three", Tickets: 3},
+		{AuthorDisplayName: "also one", Tickets: 1},
+	}
+	got := captureStdout(t, func() { printOdds(pool, 5) })
+	want := `odds of being drawn first:
+   60.00%     3 tickets  three
+   20.00%     1 tickets  one
+   20.00%     1 tickets  also one
+`
+	if got != want {
+		t.Errorf("printOdds() printed\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+)
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTime parses a timestamp flag. Timestamps without an offset are read in
+// the named time zone, or the local one when zone is empty.
+func parseTime(value string, zone string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	loc := time.Local
+	if zone != "" {
+		var err error
+		loc, err = time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown time zone %q", zone)
+		}
+	}
+	for _, layout := range timeLayouts {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("could not parse time %q, use a format like 2006-01-02 15:04", value)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		value string
+		zone  string
+		want  time.Time
+	}{
+		{"2021-08-01T20:00:00Z", "America/New_York", time.Date(2021, 8, 1, 20, 0, 0, 0, time.UTC)},
+		{"2021-08-01T20:00:00+02:00", "", time.Date(2021, 8, 1, 18, 0, 0, 0, time.UTC)},
+		{"2021-08-01T20:00:00", "UTC", time.Date(2021, 8, 1, 20, 0, 0, 0, time.UTC)},
+		{"2021-08-01T20:00", "UTC", time.Date(2021, 8, 1, 20, 0, 0, 0, time.UTC)},
+		{"2021-08-01 20:00:30", "UTC", time.Date(2021, 8, 1, 20, 0, 30, 0, time.UTC)},
+		{"2021-08-01 20:00", "America/New_York", time.Date(2021, 8, 1, 20, 0, 0, 0, newYork)},
+		{"2021-08-01", "America/New_York", time.Date(2021, 8, 1, 0, 0, 0, 0, newYork)},
+		{"2021-08-01 20:00", "", time.Date(2021, 8, 1, 20, 0, 0, 0, time.Local)},
+	}
+	for _, test := range tests {
+		t.Run(test.value+" "+test.zone, func(t *testing.T) {
+			got, err := parseTime(test.value, test.zone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(test.want) {
+				t.Errorf("parseTime() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeErrors(t *testing.T) {
+	tests := []struct {
+		value string
+		zone  string
+	}{
+		{"yesterday", ""},
+		{"08/01/2021", ""},
+		{"2021-08-01", "Mars/Olympus_Mons"},
+	}
+	for _, test := range tests {
+		if got, err := parseTime(test.value, test.zone); err == nil {
+			t.Errorf("parseTime(%q, %q) = %v, want an error", test.value, test.zone, got)
+		}
+	}
+	if got, err := parseTime("", ""); got != nil || err != nil {
+		t.Errorf("parseTime(\"\") = %v, %v, want nil", got, err)
+	}
+}
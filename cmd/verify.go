@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/amanzanero/yt/youtube"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var published youtube.Published
+
+func init() {
+	localCmd := &cobra.Command{
+		Use:   "verify <record>",
+		Short: "Recompute the winners of a saved draw",
+		Long: `This command recomputes a draw from its record without calling the youtube API and checks the winners and the seed commitment.
+
+The record file holds its own commitment, so whoever wrote it could have
+rewritten both the seed and the commitment. Pass the commitment that was
+published before the draw with --commitment to check the seed against it, and
+for draws with a beacon, the published --snapshot-digest and --beacon too.
+
+Redraws of winners who failed the subscriber check are replayed from the
+record. Verify checks that the rules had the check on and that every reason is
+one it gives, but not the reasons themselves: they are the word of whoever ran
+the draw.`,
+		Example: "yt winner verify ~/.yt/draws/20210801-150405-a1b2c3.json --commitment 9f86d08188...",
+		Args:    cobra.ExactArgs(1),
+		Run:     verifyCmd,
+	}
+	localCmd.Flags().StringVar(&published.Commitment, "commitment", "", "the commitment published before the draw")
+	localCmd.Flags().StringVar(&published.SnapshotDigest, "snapshot-digest", "", "the entrant snapshot sha256 published before the beacon")
+	localCmd.Flags().StringVar(&published.Beacon, "beacon", "", "the public beacon value the draw used")
+	winnerCommand.AddCommand(localCmd)
+}
+
+func verifyCmd(_ *cobra.Command, args []string) {
+	record, err := youtube.ReadDrawRecord(args[0])
+	if err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("draw %s: %d comments\n", record.Id, len(record.Entrants))
+	fmt.Printf("commitment: %s\n", record.Commitment)
+	fmt.Printf("seed: %s\n", record.Seed)
+	if record.Beacon != "" {
+		fmt.Printf("snapshot sha256: %s\n", record.SnapshotDigest)
+		fmt.Printf("beacon: %s\n", record.Beacon)
+	}
+	if err := record.Verify(published); err != nil {
+		color.Red("yt: %v", err)
+		os.Exit(1)
+	}
+	for _, redraw := range record.Redraws {
+		fmt.Printf("Redraw: \"%s\" (%s)\n", redraw.AuthorDisplayName, redraw.Reason)
+	}
+	for _, reroll := range record.Rerolls {
+		fmt.Printf("Reroll: \"%s\" replaced by \"%s\" (%s)\n",
+			reroll.Disqualified.AuthorDisplayName, reroll.Promoted.AuthorDisplayName, reroll.Reason)
+	}
+	for i, winner := range record.Winners {
+		fmt.Printf("Winner #%d: \"%s\"\n", i+1, winner.AuthorDisplayName)
+	}
+	color.Green("verified: the record reproduces the same winners")
+	if published.Commitment == "" {
+		color.Yellow("the seed was not checked against a published commitment, pass --commitment")
+	}
+	if len(record.Redraws) > 0 {
+		color.Yellow("the redraw reasons are the word of whoever ran the draw, they were not checked")
+	}
+	if record.Beacon != "" && (published.SnapshotDigest == "" || published.Beacon == "") {
+		color.Yellow("the beacon was not checked against the published one, pass --snapshot-digest and --beacon")
+	}
+}
@@ -1,59 +1,128 @@
 package cmd
 
 import (
-	"fmt"
 	"github.com/amanzanero/yt/youtube"
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"os"
-	"time"
 )
 
-var winnerCount int
+var winnerSpec giveawaySpec
+
+var winnerCommand = &cobra.Command{
+	Use:   "winner <video or playlist url>...",
+	Short: "Print out youtube winner",
+	Long: `This command prints out random winner(s)
+
+Draws are reproducible: the winners are derived from a seed, and every draw is
+saved as a record holding the entrants, the seed, the rules and the result.
+Anyone with the record can recompute the winners with "yt winner verify".
+
+With --commit the draw is run in three published steps:
+
+  1. the SHA-256 of the seed is printed and the draw waits while it is
+     published, before entries close;
+  2. once entries are fetched, the SHA-256 of the entrant snapshot is printed
+     and the draw waits while it is published too;
+  3. a public random value announced after that, such as a drand round or a
+     block hash, is entered as the beacon and mixed into the seed.
+
+This proves the seed was fixed before the entrants were known and that the
+entrants were fixed before anyone, the owner included, could tell who would
+win. It does not prove the snapshot holds every comment that was made: the
+owner can still delete or hold comments before the snapshot is taken.
+
+The entrant snapshot written with --entrants-csv lists every entrant's display
+name, ticket count and SHA-256 of the commitment followed by their channel ID.
+Entrants can find themselves by hashing their own channel ID that way. The
+hashes differ from draw to draw, but they are not private: channel IDs are
+public, so anyone can check whether a given channel entered.
+
+Several video URLs, or a playlist URL, can be given to merge their entrants.
+Everyone still holds one ticket, unless --video-weight is given: then everyone
+holds the weight of every video they commented on.
+
+With --live the entrants are taken from the chat of an active broadcast
+instead. The chat is collected until --closes, and only chatters who typed the
+entry keyword given with --contains or --match can win. Chat sent before the
+command started cannot be read, so start it before --opens. Chat messages
+cannot be replied to, so --notify does not work with --live.
+
+With --entrants-file the entrants are read from an export and every rule works
+the same without calling the API, unless a rule needs to look channels up. The
+export is a JSON array, newline delimited JSON (.ndjson, .jsonl) or CSV (.csv)
+of entrants with these fields, CSV using them as header names:
+
+  commentId          required
+  authorChannelId    required
+  authorDisplayName  required in CSV
+  text               required in CSV
+  publishedAt        required, RFC 3339
+  updatedAt          RFC 3339, defaults to publishedAt
+  videoId, likeCount, replyCount`,
+	Example: `yt winner https://www.youtube.com/watch?v=oYBGPVwNK2c&ab_channel=Katherout
+yt winner --prize "Grand prize=1" --prize "Runner-up=5" https://www.youtube.com/watch?v=oYBGPVwNK2c
+yt winner --mode all https://www.youtube.com/playlist?list=PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf
+yt winner --entrants-file entrants.csv --owner-channel-id UCxyz --contains giveaway
+yt winner --live https://www.youtube.com/watch?v=5qap5aO4i9A --contains '!enter' --opens '2021-08-01 20:00' --closes '2021-08-01 20:15'`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if winnerSpec.Live != "" || winnerSpec.EntrantsFile != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: winnerCmd,
+}
 
 func init() {
-	localCmd := &cobra.Command{
-		Use:     "winner",
-		Short:   "Print out youtube winner",
-		Long:    `This command prints out random winner(s)`,
-		Example: "yt winner https://www.youtube.com/watch?v=oYBGPVwNK2c&ab_channel=Katherout",
-		Args:    cobra.MinimumNArgs(1),
-		Run:     winnerCmd,
-	}
-	localCmd.Flags().IntVarP(&winnerCount, "count", "c", 1, "Total number of winners. Each user is considered equally regardless of number of comments.")
+	localCmd := winnerCommand
+	spec := &winnerSpec
+	localCmd.Flags().IntVarP(&spec.Count, "count", "c", 1, "Total number of winners. Each user enters once however many comments they write, unless --video-weight or the ticket flags give them more tickets.")
+	localCmd.Flags().StringArrayVar(&spec.PrizeFlags, "prize", nil, "prize tier as NAME=COUNT, drawn in the order given and replacing --count (repeatable)")
+	localCmd.Flags().StringVar(&spec.Seed, "seed", "", "seed for the draw, a random one is generated when empty")
+	localCmd.Flags().BoolVar(&spec.Commit, "commit", false, "publish the seed and entrant commitments and mix in a public beacon, see above")
+	localCmd.Flags().StringVar(&spec.Record, "record", "", "path to save the draw record (default ~/.yt/draws/<id>.json)")
+	localCmd.Flags().StringVar(&spec.Snapshot, "entrants-csv", "", "write the entrants to this CSV file and wait after printing its SHA-256, so both can be published before the draw")
+	localCmd.Flags().StringArrayVar(&spec.Entry.Contains, "contains", nil, "only count comments containing this text, ignoring case (repeatable)")
+	localCmd.Flags().StringArrayVar(&spec.Entry.Matches, "match", nil, "only count comments matching this regular expression (repeatable)")
+	localCmd.Flags().StringArrayVar(&spec.Entry.Hashtags, "hashtag", nil, "only count comments using this hashtag (repeatable)")
+	localCmd.Flags().IntVar(&spec.Entry.MinMentions, "min-mentions", 0, "only count comments with at least this many distinct @mentions")
+	localCmd.Flags().IntVar(&spec.Entry.MinLength, "min-length", 0, "only count comments with at least this many characters")
+	localCmd.Flags().StringVar(&spec.Window.Opens, "opens", "", "only count comments published at or after this time")
+	localCmd.Flags().StringVar(&spec.Window.Closes, "closes", "", "only count comments published at or before this time")
+	localCmd.Flags().StringVar(&spec.Window.Timezone, "timezone", "", "IANA time zone for --opens and --closes without an offset (default local)")
+	localCmd.Flags().StringVar(&spec.Window.LateEdits, "late-edits", youtube.RejectLateEdits, "what to do with comments edited after --closes: reject or flag")
+	localCmd.Flags().BoolVar(&spec.SubscribersOnly, "subscribers-only", false, "redraw winners who are not subscribed to the channel")
+	localCmd.Flags().StringVar(&spec.PrivateSubscriptions, "private-subscriptions", youtube.RedrawPrivate, "what to do with winners whose subscriptions are private: redraw or allow")
+	localCmd.Flags().StringVar(&spec.Mode, "mode", youtube.AnyVideo, "with several videos, enter people who commented on any or all of them")
+	localCmd.Flags().StringToIntVar(&spec.VideoWeights, "video-weight", nil, "tickets commenting on a video is worth, as VIDEO_ID=N, added up over the videos (default 1)")
+	localCmd.Flags().Int64Var(&spec.Tickets.PerComment, "tickets-per-comment", 0, "bonus tickets for every eligible comment")
+	localCmd.Flags().Int64Var(&spec.Tickets.PerLike, "tickets-per-like", 0, "bonus tickets for every like on an eligible comment")
+	localCmd.Flags().Int64Var(&spec.Tickets.PerReply, "tickets-per-reply", 0, "bonus tickets for every reply in an eligible comment's thread")
+	localCmd.Flags().Int64Var(&spec.Tickets.Member, "member-tickets", 0, "bonus tickets for channel members listed in --members-file")
+	localCmd.Flags().StringVar(&spec.Tickets.MembersFile, "members-file", "", "file of channel member channel IDs, one per line")
+	localCmd.Flags().Int64Var(&spec.Tickets.Max, "max-tickets", 0, "most tickets a single person can hold (default no limit)")
+	localCmd.Flags().BoolVar(&spec.Odds, "odds", false, "print every entrant's odds before the draw, even without weighting")
+	localCmd.Flags().BoolVar(&spec.Exclude.Owner, "exclude-owner", true, "keep the channel that uploaded the video out of the draw")
+	localCmd.Flags().StringVar(&spec.Exclude.Moderators, "moderators", "", "file of moderator channel IDs to keep out of the draw, one per line")
+	localCmd.Flags().StringVar(&spec.Exclude.Blocklist, "blocklist", "", "file of channel IDs to keep out of the draw, one per line")
+	localCmd.Flags().IntVar(&spec.Exclude.RecentWinners, "exclude-recent-winners", 0, "keep people who won in the last N days, according to the winners ledger, out of the draw")
+	localCmd.Flags().IntVar(&spec.Channels.MinAgeDays, "min-channel-age", 0, "keep channels created less than this many days ago out of the draw")
+	localCmd.Flags().Uint64Var(&spec.Channels.MinVideos, "min-videos", 0, "keep channels with fewer public videos out of the draw")
+	localCmd.Flags().Uint64Var(&spec.Channels.MinSubscribers, "min-subscribers", 0, "keep channels with fewer or hidden subscribers out of the draw")
+	localCmd.Flags().IntVar(&spec.ClaimDays, "claim-days", 0, "days winners have to claim their prize before they expire, tracked with \"yt giveaway winners\" (default no deadline)")
+	localCmd.Flags().IntVar(&spec.Alternates, "alternates", 3, "number of ordered alternates to draw for rerolls")
+	localCmd.Flags().BoolVar(&spec.Notify.Enabled, "notify", false, "reply to each winning comment to let the winner know")
+	localCmd.Flags().StringVar(&spec.Notify.Template, "message", youtube.DefaultNotification, "reply template, {winner} is the winner's name, {prize} their prize and {claim} the claim instructions")
+	localCmd.Flags().StringVar(&spec.Notify.Claim, "claim", "", "claim instructions for the {claim} placeholder")
+	localCmd.Flags().BoolVar(&spec.Notify.DryRun, "dry-run", false, "with --notify, print the winners and replies without saving the draw or posting the replies")
+	localCmd.Flags().StringVar(&spec.Live, "live", "", "draw from the chat of this active broadcast instead of comments")
+	localCmd.Flags().StringVar(&spec.EntrantsFile, "entrants-file", "", "draw from an entrant export instead of the API")
+	localCmd.Flags().StringVar(&spec.Owner, "owner-channel-id", "", "with --entrants-file, the channel ID --exclude-owner keeps out, required unless --exclude-owner=false")
+	localCmd.Flags().BoolVar(&spec.Reveal.Enabled, "reveal", false, "reveal the winners with an animation for streams, the winners are drawn before it starts")
+	localCmd.Flags().StringVar(&spec.Reveal.Addr, "reveal-addr", "", "with --reveal, also serve the reveal as a web page on this address, e.g. localhost:8091")
 	rootCmd.AddCommand(localCmd)
 }
 
 func winnerCmd(_ *cobra.Command, args []string) {
-	tokenProvider, err := youtube.NewTokenProvider(config)
-	cobra.CheckErr(err)
-
-	youtubeService, err := youtube.New(youtube.WithTokenSource(tokenProvider))
-	if err != nil {
-		color.Red("yt: %v", err)
-		os.Exit(1)
-	}
-
-	start := time.Now()
-	winners, err := youtubeService.RandomCommenters(args[0], winnerCount)
-	total := time.Since(start).Milliseconds()
-	if err != nil {
-		color.Red("yt: %v", err)
-	}
-	for i, winner := range winners {
-		color.Blue("Winner #%d: \"%s\"\n", i+1, winner)
-	}
-	fmt.Printf("took %dms\n", total)
-
-	// store token in case it refreshed
-	token, err := tokenProvider.Token()
-	if err != nil {
-		color.Red("yt: could not save token")
-		os.Exit(1)
-	}
-	err = youtube.WriteToken(token)
-	if err != nil {
-		color.Red("yt: could not save token")
-		os.Exit(1)
-	}
+	winnerSpec.Videos = args
+	runGiveaway(&winnerSpec)
 }
@@ -0,0 +1,92 @@
+package youtube
+
+import (
+	"fmt"
+	"google.golang.org/api/youtube/v3"
+	"sort"
+	"time"
+)
+
+var channelBatchSize = 50
+
+// ChannelRules screen out throwaway channels before a draw. Zero values turn a
+// rule off.
+type ChannelRules struct {
+	// MinAgeDays is how long ago the channel must have been created.
+	MinAgeDays int `json:"minAgeDays,omitempty"`
+	// MinVideos is the minimum number of public videos.
+	MinVideos uint64 `json:"minVideos,omitempty"`
+	// MinSubscribers is the minimum subscriber count. Channels hiding their
+	// subscriber count do not meet it.
+	MinSubscribers uint64 `json:"minSubscribers,omitempty"`
+}
+
+// Enabled reports whether any channel rule is set.
+func (c ChannelRules) Enabled() bool {
+	return c.MinAgeDays > 0 || c.MinVideos > 0 || c.MinSubscribers > 0
+}
+
+// ScreenChannels looks up the channel of every entrant and adds an exclusion
+// for every channel rule each channel fails. Channels the API does not return,
+// deleted or terminated ones, are excluded as not found. The exclusions are
+// kept in the record so the draw can be verified without the API.
+func (s *Service) ScreenChannels(record *DrawRecord) error {
+	rules := record.Rules.Channels
+	if !rules.Enabled() {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	channelIds := make([]string, 0)
+	for _, entrant := range record.Entrants {
+		if entrant.AuthorChannelId != "" && !seen[entrant.AuthorChannelId] {
+			seen[entrant.AuthorChannelId] = true
+			channelIds = append(channelIds, entrant.AuthorChannelId)
+		}
+	}
+	sort.Strings(channelIds)
+
+	createdBefore := record.DrawnAt.AddDate(0, 0, -rules.MinAgeDays)
+	for start := 0; start < len(channelIds); start += channelBatchSize {
+		end := start + channelBatchSize
+		if end > len(channelIds) {
+			end = len(channelIds)
+		}
+		resp, err := s.ytService.Channels.List([]string{"snippet", "statistics"}).Id(channelIds[start:end]...).MaxResults(int64(channelBatchSize)).Do()
+		if err != nil {
+			return err
+		}
+		channels := make(map[string]*youtube.Channel)
+		for _, channel := range resp.Items {
+			channels[channel.Id] = channel
+		}
+		for _, channelId := range channelIds[start:end] {
+			for _, reason := range rules.failing(channels[channelId], createdBefore) {
+				record.Rules.Exclusions = append(record.Rules.Exclusions, Exclusion{AuthorChannelId: channelId, Reason: reason})
+			}
+		}
+	}
+	return nil
+}
+
+// failing returns the reason for every rule channel fails. A nil channel was
+// not found, which is the only reason given for it.
+func (c ChannelRules) failing(channel *youtube.Channel, createdBefore time.Time) []string {
+	if channel == nil || channel.Snippet == nil || channel.Statistics == nil {
+		return []string{"channel not found"}
+	}
+	reasons := make([]string, 0)
+	publishedAt, _ := time.Parse(time.RFC3339, channel.Snippet.PublishedAt)
+	if c.MinAgeDays > 0 && publishedAt.After(createdBefore) {
+		reasons = append(reasons, fmt.Sprintf("channel younger than %d days", c.MinAgeDays))
+	}
+	if c.MinVideos > 0 && channel.Statistics.VideoCount < c.MinVideos {
+		reasons = append(reasons, fmt.Sprintf("fewer than %d public videos", c.MinVideos))
+	}
+	if c.MinSubscribers > 0 && channel.Statistics.HiddenSubscriberCount {
+		reasons = append(reasons, "hidden subscriber count")
+	} else if c.MinSubscribers > 0 && channel.Statistics.SubscriberCount < c.MinSubscribers {
+		reasons = append(reasons, fmt.Sprintf("fewer than %d subscribers", c.MinSubscribers))
+	}
+	return reasons
+}
@@ -0,0 +1,44 @@
+package youtube
+
+import (
+	"fmt"
+	"google.golang.org/api/youtube/v3"
+	"testing"
+	"time"
+)
+
+func TestChannelRulesFailing(t *testing.T) {
+	createdBefore := time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)
+	channel := func(publishedAt string, videos uint64, subscribers uint64, hidden bool) *youtube.Channel {
+		return &youtube.Channel{
+			Snippet: &youtube.ChannelSnippet{PublishedAt: publishedAt},
+			Statistics: &youtube.ChannelStatistics{
+				VideoCount:            videos,
+				SubscriberCount:       subscribers,
+				HiddenSubscriberCount: hidden,
+			},
+		}
+	}
+	rules := ChannelRules{MinAgeDays: 30, MinVideos: 1, MinSubscribers: 10}
+
+	tests := []struct {
+		name    string
+		channel *youtube.Channel
+		want    []string
+	}{
+		{"passes", channel("2020-01-01T00:00:00Z", 5, 100, false), []string{}},
+		{"young", channel("2021-08-02T00:00:00Z", 5, 100, false), []string{"channel younger than 30 days"}},
+		{"every rule", channel("2021-08-02T00:00:00Z", 0, 1, false), []string{
+			"channel younger than 30 days", "fewer than 1 public videos", "fewer than 10 subscribers",
+		}},
+		{"hidden subscribers", channel("2020-01-01T00:00:00Z", 5, 0, true), []string{"hidden subscriber count"}},
+		{"not found", nil, []string{"channel not found"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := rules.failing(test.channel, createdBefore); fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("failing() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package youtube
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ClaimPending is a winner who has not claimed their prize yet.
+	ClaimPending = "pending"
+	// ClaimClaimed is a winner who claimed their prize.
+	ClaimClaimed = "claimed"
+	// ClaimExpired is a winner who missed the claim deadline and can be rerolled.
+	ClaimExpired = "expired"
+)
+
+var claimsHeader = []string{"draw", "prize", "winner", "channel_id", "won_at", "deadline", "status", "shipping"}
+
+// Claim is the claim state of a winner. It is kept apart from the draw result
+// so that updating it never changes what Verify recomputes.
+type Claim struct {
+	Status    string    `json:"status"`
+	Shipping  string    `json:"shipping,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// WinnerClaim is a current winner of a draw together with their claim.
+type WinnerClaim struct {
+	DrawId   string
+	Winner   *Winner
+	WonAt    time.Time
+	Deadline *time.Time
+	Status   string
+	Shipping string
+}
+
+// WinnerClaims returns the claims of the current winners. Pending winners
+// past their deadline at now are reported as expired.
+func (r *DrawRecord) WinnerClaims(now time.Time) []*WinnerClaim {
+	// promoted alternates won when they were promoted, not at the draw
+	wonAt := make(map[string]time.Time)
+	for _, reroll := range r.Rerolls {
+		wonAt[reroll.Promoted.key()] = reroll.RerolledAt
+	}
+
+	claims := make([]*WinnerClaim, 0, len(r.Winners))
+	for _, winner := range r.Winners {
+		claim := &WinnerClaim{
+			DrawId: r.Id,
+			Winner: winner,
+			WonAt:  r.DrawnAt,
+			Status: ClaimPending,
+		}
+		if at, ok := wonAt[winner.key()]; ok {
+			claim.WonAt = at
+		}
+		if r.ClaimDays > 0 {
+			deadline := claim.WonAt.AddDate(0, 0, r.ClaimDays)
+			claim.Deadline = &deadline
+		}
+		if state, ok := r.Claims[winner.key()]; ok {
+			claim.Status = state.Status
+			claim.Shipping = state.Shipping
+		}
+		if claim.Status == ClaimPending && claim.Deadline != nil && now.After(*claim.Deadline) {
+			claim.Status = ClaimExpired
+		}
+		claims = append(claims, claim)
+	}
+	return claims
+}
+
+// SetClaim updates the claim of the winner matching who, a channel ID, comment
+// ID or display name. An empty shipping status keeps the current one.
+func (r *DrawRecord) SetClaim(who string, status string, shipping string) (*Winner, error) {
+	if err := ValidateClaimStatus(status); err != nil {
+		return nil, err
+	}
+	winner, err := r.findWinner(who)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Claims == nil {
+		r.Claims = make(map[string]*Claim)
+	}
+	claim, ok := r.Claims[winner.key()]
+	if !ok {
+		claim = new(Claim)
+		r.Claims[winner.key()] = claim
+	}
+	claim.Status = status
+	if shipping != "" {
+		claim.Shipping = shipping
+	}
+	claim.UpdatedAt = time.Now().UTC()
+	return winner, nil
+}
+
+// ValidateClaimStatus reports statuses other than pending, claimed or expired.
+func ValidateClaimStatus(status string) error {
+	if status != ClaimPending && status != ClaimClaimed && status != ClaimExpired {
+		return fmt.Errorf("claim status must be %q, %q or %q", ClaimPending, ClaimClaimed, ClaimExpired)
+	}
+	return nil
+}
+
+// FindDrawRecord returns the path of a draw record given either its path or
+// its ID, in DrawsDir or in the index of records saved elsewhere.
+func FindDrawRecord(draw string) string {
+	if _, err := os.Stat(draw); err == nil {
+		return draw
+	}
+	path := filepath.Join(DrawsDir(), draw+".json")
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	index, _ := readDrawIndex()
+	if indexed, ok := index[draw]; ok {
+		return indexed
+	}
+	return path
+}
+
+// ReadDrawRecords reads every record in DrawsDir and every indexed record
+// saved elsewhere that still exists, oldest draw first. A missing directory
+// holds no records.
+func ReadDrawRecords() ([]*DrawRecord, error) {
+	paths := make([]string, 0)
+	files, err := ioutil.ReadDir(DrawsDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+			paths = append(paths, filepath.Join(DrawsDir(), file.Name()))
+		}
+	}
+	index, err := readDrawIndex()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range index {
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+
+	records := make([]*DrawRecord, 0, len(paths))
+	for _, path := range paths {
+		record, err := ReadDrawRecord(path)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].DrawnAt.Before(records[j].DrawnAt)
+	})
+	return records, nil
+}
+
+// ClaimsCsv renders winner claims as CSV with a header row.
+func ClaimsCsv(claims []*WinnerClaim) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	if err := w.Write(claimsHeader); err != nil {
+		return nil, err
+	}
+	for _, claim := range claims {
+		deadline := ""
+		if claim.Deadline != nil {
+			deadline = claim.Deadline.Format(time.RFC3339)
+		}
+		row := []string{
+			claim.DrawId,
+			claim.Winner.Prize,
+			claim.Winner.AuthorDisplayName,
+			claim.Winner.AuthorChannelId,
+			claim.WonAt.Format(time.RFC3339),
+			deadline,
+			claim.Status,
+			claim.Shipping,
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
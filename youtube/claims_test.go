@@ -0,0 +1,68 @@
+package youtube
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadDrawRecords(t *testing.T) {
+	home = t.TempDir()
+	inDrawsDir := testRecord(t)
+	inDrawsDir.DrawnAt = inDrawsDir.DrawnAt.Add(-time.Hour)
+	if _, err := inDrawsDir.Create(""); err != nil {
+		t.Fatal(err)
+	}
+	elsewhere := testRecord(t)
+	custom := filepath.Join(t.TempDir(), "summer.json")
+	if _, err := elsewhere.Create(custom); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := elsewhere.Create(custom); err == nil {
+		t.Error("Create() overwrote an existing record")
+	}
+
+	records, err := ReadDrawRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Id != inDrawsDir.Id || records[1].Id != elsewhere.Id {
+		t.Errorf("ReadDrawRecords() returned %d records, want both, oldest first", len(records))
+	}
+	if got := FindDrawRecord(elsewhere.Id); got != custom {
+		t.Errorf("FindDrawRecord(%q) = %q, want %q", elsewhere.Id, got, custom)
+	}
+	if got, want := FindDrawRecord(inDrawsDir.Id), filepath.Join(DrawsDir(), inDrawsDir.Id+".json"); got != want {
+		t.Errorf("FindDrawRecord(%q) = %q, want %q", inDrawsDir.Id, got, want)
+	}
+}
+
+func TestWinnerClaims(t *testing.T) {
+	record := testRecord(t)
+	record.ClaimDays = 7
+	if _, err := record.SetClaim("UC3", ClaimClaimed, "shipped"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := record.SetClaim("UC3", "lost", ""); err == nil {
+		t.Error("SetClaim() accepted status \"lost\"")
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want []string
+	}{
+		{"before deadline", record.DrawnAt.AddDate(0, 0, 1), []string{ClaimClaimed, ClaimPending}},
+		{"after deadline", record.DrawnAt.AddDate(0, 0, 8), []string{ClaimClaimed, ClaimExpired}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := record.WinnerClaims(test.now)
+			for i, claim := range claims {
+				if claim.Status != test.want[i] {
+					t.Errorf("claim %d = %s, want %s", i, claim.Status, test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package youtube
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	ham = iota
+	spam
+)
+
+const (
+	// linkFeature, phoneFeature and lookalikeFeature are tokens added for
+	// traits that words alone miss. Words are lowercase, so they never clash.
+	linkFeature      = "Feature:link"
+	phoneFeature     = "Feature:phone"
+	lookalikeFeature = "Feature:lookalike"
+)
+
+var (
+	NoTrainingErr = errors.New("training needs both approved and rejected comments")
+
+	linkPattern  = regexp.MustCompile(`(?i)https?://|www\.|\b[a-z0-9-]+\.(com|net|org|io|xyz|me|ly|gg|co|info|site|top)\b`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s().-]{7,}\d`)
+	// confusables folds characters impersonators swap in for lookalike names
+	confusables = strings.NewReplacer(
+		"0", "o", "1", "l", "i", "l", "|", "l", "3", "e", "4", "a", "@", "a",
+		"5", "s", "$", "s", "7", "t", "rn", "m", "vv", "w",
+		"а", "a", "е", "e", "о", "o", "р", "p", "с", "c", "х", "x", "у", "y", "і", "l",
+	)
+)
+
+// LabeledComment is a past moderation decision to train a Classifier on.
+// Label is Approve, Reject or Ban.
+type LabeledComment struct {
+	Label             string
+	AuthorChannelId   string
+	AuthorDisplayName string
+	Text              string
+}
+
+// Classifier is a naive Bayes spam classifier over the words of a comment,
+// whether it has links or phone numbers, and whether its author's name looks
+// like one of OwnerNames. Authors in OwnerChannelIds are the owners themselves
+// and never look like impersonators. It runs entirely offline.
+type Classifier struct {
+	OwnerNames      []string
+	OwnerChannelIds []string
+	counts          [2]map[string]int
+	tokens          [2]int
+	comments        [2]int
+	vocabulary      map[string]bool
+}
+
+// ReadLabeledComments reads a CSV file with label and text columns, and an
+// optional authorDisplayName and authorChannelId columns. Labels are approve, reject or ban.
+func ReadLabeledComments(path string) ([]*LabeledComment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"label", "text"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%s: missing column %q", path, name)
+		}
+	}
+
+	examples := make([]*LabeledComment, 0)
+	for line := 2; ; line++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			return examples, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		example := &LabeledComment{
+			Label: strings.ToLower(strings.TrimSpace(row[columns["label"]])),
+			Text:  row[columns["text"]],
+		}
+		if i, ok := columns["authorDisplayName"]; ok {
+			example.AuthorDisplayName = row[i]
+		}
+		if i, ok := columns["authorChannelId"]; ok {
+			example.AuthorChannelId = strings.TrimSpace(row[i])
+		}
+		if example.Label != Approve && example.Label != Reject && example.Label != Ban {
+			return nil, fmt.Errorf("%s:%d: label must be %q, %q or %q", path, line, Approve, Reject, Ban)
+		}
+		examples = append(examples, example)
+	}
+}
+
+// TrainClassifier trains a classifier on past decisions. Rejected and banned
+// comments are spam, approved ones are not.
+func TrainClassifier(examples []*LabeledComment, ownerNames []string, ownerChannelIds []string) (*Classifier, error) {
+	c := &Classifier{
+		OwnerNames:      ownerNames,
+		OwnerChannelIds: ownerChannelIds,
+		counts:          [2]map[string]int{make(map[string]int), make(map[string]int)},
+		vocabulary:      make(map[string]bool),
+	}
+	for _, example := range examples {
+		class := spam
+		if example.Label == Approve {
+			class = ham
+		}
+		c.comments[class]++
+		for _, token := range c.Features(example.AuthorChannelId, example.AuthorDisplayName, example.Text) {
+			c.counts[class][token]++
+			c.tokens[class]++
+			c.vocabulary[token] = true
+		}
+	}
+	if c.comments[ham] == 0 || c.comments[spam] == 0 {
+		return nil, NoTrainingErr
+	}
+	return c, nil
+}
+
+// SpamProbability returns the probability that a comment is spam, from 0 to 1.
+// Tokens never seen in training are ignored.
+func (c *Classifier) SpamProbability(authorChannelId string, authorDisplayName string, text string) float64 {
+	total := float64(c.comments[ham] + c.comments[spam])
+	var logLikelihood [2]float64
+	for class := range logLikelihood {
+		logLikelihood[class] = math.Log(float64(c.comments[class]) / total)
+	}
+	vocabulary := float64(len(c.vocabulary))
+	for _, token := range c.Features(authorChannelId, authorDisplayName, text) {
+		if !c.vocabulary[token] {
+			continue
+		}
+		for class := range logLikelihood {
+			// Laplace smoothing keeps tokens seen in one class only finite
+			logLikelihood[class] += math.Log((float64(c.counts[class][token]) + 1) / (float64(c.tokens[class]) + vocabulary))
+		}
+	}
+	return 1 / (1 + math.Exp(logLikelihood[ham]-logLikelihood[spam]))
+}
+
+// Features returns the tokens of a comment: its lowercase words and a token
+// for each trait it has.
+func (c *Classifier) Features(authorChannelId string, authorDisplayName string, text string) []string {
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if linkPattern.MatchString(text) {
+		tokens = append(tokens, linkFeature)
+	}
+	if phonePattern.MatchString(text) {
+		tokens = append(tokens, phoneFeature)
+	}
+	if c.lookalike(authorChannelId, authorDisplayName) {
+		tokens = append(tokens, lookalikeFeature)
+	}
+	return tokens
+}
+
+// lookalike reports whether name imitates an owner name: once folded, it
+// contains the owner name or is one edit away from it. The owners' own
+// channels are never lookalikes. Without their channel IDs the owner's exact
+// name cannot be told apart from the owner, so it is not counted.
+func (c *Classifier) lookalike(channelId string, name string) bool {
+	if channelId != "" && containsId(c.OwnerChannelIds, channelId) {
+		return false
+	}
+	folded := foldName(name)
+	if folded == "" {
+		return false
+	}
+	for _, owner := range c.OwnerNames {
+		ownerFolded := foldName(owner)
+		if len(ownerFolded) < 4 {
+			continue
+		}
+		if len(c.OwnerChannelIds) == 0 && strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(owner)) {
+			continue
+		}
+		if strings.Contains(folded, ownerFolded) || editDistance(folded, ownerFolded) <= 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func foldName(name string) string {
+	folded := confusables.Replace(strings.ToLower(name))
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r
+		}
+		return -1
+	}, folded)
+}
+
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,139 @@
+package youtube
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFoldName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Katherout", "katherout"},
+		{"K4th3r0ut", "katherout"},
+		{"Kathe rout!", "katherout"},
+		{"Kаtherout", "katherout"},
+		{"Official", "offlclal"},
+		{"vvinner", "wlnner"},
+		{"123", "le"},
+		{"☆☆☆", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := foldName(test.name); got != test.want {
+				t.Errorf("foldName(%q) = %q, want %q", test.name, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"abc", "abc", 0},
+		{"abc", "abd", 1},
+		{"abc", "ab", 1},
+		{"abc", "acb", 2},
+		{"kitten", "sitting", 3},
+	}
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b); got != test.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestLookalike(t *testing.T) {
+	withIds := &Classifier{OwnerNames: []string{"Katherout", "Bob"}, OwnerChannelIds: []string{"UCowner"}}
+	withoutIds := &Classifier{OwnerNames: []string{"Katherout", "Bob"}}
+	tests := []struct {
+		name       string
+		classifier *Classifier
+		channelId  string
+		author     string
+		want       bool
+	}{
+		{"owner", withIds, "UCowner", "Katherout", false},
+		{"owner renamed", withIds, "UCowner", "Katherout Giveaways", false},
+		{"same name", withIds, "UCother", "Katherout", true},
+		{"confusables", withIds, "UCother", "K4th3r0ut", true},
+		{"contains", withIds, "UCother", "Katherout Support", true},
+		{"one edit", withIds, "UCother", "Katherot", true},
+		{"two edits", withIds, "UCother", "Kathrot", false},
+		{"unrelated", withIds, "UCother", "Someone", false},
+		{"short owner name", withIds, "UCother", "Bob", false},
+		{"no channel", withIds, "", "Katherout", true},
+		{"exact name without ids", withoutIds, "UCother", "katherout ", false},
+		{"confusables without ids", withoutIds, "UCother", "K4th3r0ut", true},
+		{"contains without ids", withoutIds, "UCother", "Katherout Support", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.classifier.lookalike(test.channelId, test.author); got != test.want {
+				t.Errorf("lookalike(%q, %q) = %v, want %v", test.channelId, test.author, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSpamProbability(t *testing.T) {
+	examples := []*LabeledComment{
+		{Label: Approve, AuthorChannelId: "UC1", AuthorDisplayName: "Ann", Text: "great video, thanks"},
+		{Label: Approve, AuthorChannelId: "UC2", AuthorDisplayName: "Ben", Text: "love this song"},
+		{Label: Approve, AuthorChannelId: "UCowner", AuthorDisplayName: "Katherout", Text: "thanks for watching"},
+		{Label: Reject, AuthorChannelId: "UC3", AuthorDisplayName: "Katherout Support", Text: "you won! text me on whatsapp +1 555 123 4567"},
+		{Label: Ban, AuthorChannelId: "UC4", AuthorDisplayName: "K4therout", Text: "claim your prize at www.prize.xyz"},
+		{Label: Reject, AuthorChannelId: "UC5", AuthorDisplayName: "Cal", Text: "free crypto, visit https://scam.example.com"},
+	}
+	if _, err := TrainClassifier(examples[:3], []string{"Katherout"}, []string{"UCowner"}); err != NoTrainingErr {
+		t.Errorf("TrainClassifier() with approvals only = %v, want %v", err, NoTrainingErr)
+	}
+	c, err := TrainClassifier(examples, []string{"Katherout"}, []string{"UCowner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		channelId string
+		author    string
+		text      string
+		spam      bool
+	}{
+		{"ham", "UC6", "Dee", "great song, thanks", false},
+		{"owner", "UCowner", "Katherout", "thanks for watching", false},
+		{"impersonator", "UC7", "Katherоut", "you won a prize, whatsapp me", true},
+		{"link", "UC8", "Eve", "free prize at www.win.xyz", true},
+		{"phone", "UC9", "Fay", "text +44 20 7946 0958", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := c.SpamProbability(test.channelId, test.author, test.text)
+			if p < 0 || p > 1 {
+				t.Fatalf("SpamProbability() = %v, want a probability", p)
+			}
+			if spam := p >= 0.5; spam != test.spam {
+				t.Errorf("SpamProbability() = %.3f, want spam %v", p, test.spam)
+			}
+		})
+	}
+
+	// tokens never seen in training leave the prior, half the comments are spam
+	if p := c.SpamProbability("UC10", "Gus", "zzz qqq"); math.Abs(p-0.5) > 1e-9 {
+		t.Errorf("SpamProbability() of unknown words = %v, want 0.5", p)
+	}
+
+	// the same comment scores higher from an impersonator than from the owner
+	owner := c.SpamProbability("UCowner", "Katherout", "new video is up")
+	impersonator := c.SpamProbability("UC11", "Katherout", "new video is up")
+	if impersonator <= owner {
+		t.Errorf("SpamProbability() of impersonator = %.3f, want more than the owner's %.3f", impersonator, owner)
+	}
+}
@@ -0,0 +1,262 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/api/youtube/v3"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// OrderTime lists the newest comments first, the API default.
+	OrderTime = "time"
+	// OrderRelevance lists the comments the API ranks highest first.
+	OrderRelevance = "relevance"
+
+	// FormatPlain returns comment text as plain text.
+	FormatPlain = "plain"
+	// FormatHtml returns comment text as HTML, with links and formatting.
+	FormatHtml = "html"
+)
+
+// CommentOptions select the comments ListComments returns.
+type CommentOptions struct {
+	// Count is the most top-level comments returned.
+	Count int
+	// Replies fetches every reply to the comments.
+	Replies bool
+	// Order is OrderTime or OrderRelevance, the API default when empty.
+	Order string
+	// Search only returns comments containing these terms, the API does the
+	// matching.
+	Search string
+	// Format is FormatPlain or FormatHtml and sets the format of the
+	// TextDisplay of each comment, the API default when empty.
+	Format string
+	// Since drops comments published before it. Replies are not checked.
+	Since *time.Time
+	// ModerationStatus lists comments held for review or likely spam instead
+	// of published ones, see HeldForReview and LikelySpam.
+	ModerationStatus string
+}
+
+// apiTextFormat is the textFormat the API expects for format, which calls
+// plain text "plainText".
+func apiTextFormat(format string) string {
+	if format == FormatPlain {
+		return "plainText"
+	}
+	return format
+}
+
+// Validate reports options the API would reject.
+func (o CommentOptions) Validate() error {
+	if o.Order != "" && o.Order != OrderTime && o.Order != OrderRelevance {
+		return fmt.Errorf("comment order must be %q or %q", OrderTime, OrderRelevance)
+	}
+	if o.Format != "" && o.Format != FormatPlain && o.Format != FormatHtml {
+		return fmt.Errorf("comment format must be %q or %q", FormatPlain, FormatHtml)
+	}
+	if o.ModerationStatus != "" && o.ModerationStatus != HeldForReview && o.ModerationStatus != LikelySpam {
+		return fmt.Errorf("moderation status must be %q or %q", HeldForReview, LikelySpam)
+	}
+	if o.Since != nil && o.Order == OrderRelevance {
+		return fmt.Errorf("a time cutoff needs the comments in %q order", OrderTime)
+	}
+	return nil
+}
+
+func (o CommentOptions) part() []string {
+	if o.Replies {
+		return []string{"snippet", "replies"}
+	}
+	return []string{"snippet"}
+}
+
+// appendThreads appends the threads published since the cutoff and reports
+// whether the page had any, so paging can stop once the cutoff is passed.
+func (o CommentOptions) appendThreads(comments *[]*CommentThread, threads []*youtube.CommentThread) bool {
+	found := len(threads) > 0
+	if o.Since != nil {
+		found = false
+	}
+	for _, thread := range threads {
+		comment := newCommentThread(thread)
+		if o.Since != nil {
+			publishedAt, _ := time.Parse(time.RFC3339, comment.Comment.Snippet.PublishedAt)
+			if publishedAt.Before(*o.Since) {
+				continue
+			}
+			found = true
+		}
+		*comments = append(*comments, comment)
+	}
+	return found
+}
+
+// CommentThread is a top-level comment with its replies, oldest first. The
+// replies are only filled in when they were requested.
+type CommentThread struct {
+	// VideoId is empty for comments about the channel itself. VideoTitle is
+	// only filled in for channel listings.
+	VideoId         string
+	VideoTitle      string
+	Comment         *youtube.Comment
+	Replies         []*youtube.Comment
+	TotalReplyCount int64
+}
+
+func newCommentThread(thread *youtube.CommentThread) *CommentThread {
+	comment := &CommentThread{
+		VideoId:         thread.Snippet.VideoId,
+		Comment:         thread.Snippet.TopLevelComment,
+		TotalReplyCount: thread.Snippet.TotalReplyCount,
+	}
+	if thread.Replies != nil {
+		comment.Replies = sortReplies(thread.Replies.Comments)
+	}
+	return comment
+}
+
+// completeReplies fetches the full reply list of threads whose embedded
+// replies were truncated, the API only embeds a few of them.
+func (s *Service) completeReplies(threads []*CommentThread, format string) error {
+	for _, thread := range threads {
+		if int64(len(thread.Replies)) >= thread.TotalReplyCount {
+			continue
+		}
+		replies, err := s.getReplies(thread.Comment.Id, format)
+		if err != nil {
+			return err
+		}
+		thread.Replies = sortReplies(replies)
+	}
+	return nil
+}
+
+func (s *Service) getReplies(parentId string, format string) ([]*youtube.Comment, error) {
+	replies := make([]*youtube.Comment, 0)
+
+	replyRequest := s.ytService.Comments.List([]string{"snippet"}).ParentId(parentId).MaxResults(int64(maxResults))
+	if format != "" {
+		replyRequest = replyRequest.TextFormat(apiTextFormat(format))
+	}
+	resp, err := replyRequest.Do()
+	if err != nil {
+		return nil, err
+	}
+	replies = append(replies, resp.Items...)
+	for resp.NextPageToken != "" {
+		resp, err = replyRequest.PageToken(resp.NextPageToken).Do()
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, resp.Items...)
+	}
+	return replies, nil
+}
+
+// sortReplies orders replies oldest first, the API does not promise an order.
+func sortReplies(replies []*youtube.Comment) []*youtube.Comment {
+	sort.SliceStable(replies, func(i, j int) bool {
+		return replies[i].Snippet.PublishedAt < replies[j].Snippet.PublishedAt
+	})
+	return replies
+}
+
+var HandleErr = errors.New("channel handles are not supported, use the channel ID or URL")
+
+// resolveChannelId returns the ID of the channel ref names: "mine", a channel
+// ID, a channel URL, a legacy username or a /user/ URL.
+func (s *Service) resolveChannelId(ref string) (string, error) {
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		u, err := url.ParseRequestURI(ref)
+		if err != nil {
+			return "", err
+		}
+		if u.Host != "www.youtube.com" && u.Host != "youtube.com" {
+			return "", InvalidUrlErr
+		}
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		switch {
+		case len(segments) >= 2 && segments[0] == "channel":
+			return segments[1], nil
+		case len(segments) >= 2 && segments[0] == "user":
+			ref = segments[1]
+		case strings.HasPrefix(segments[0], "@"), segments[0] == "c":
+			return "", HandleErr
+		default:
+			return "", InvalidUrlErr
+		}
+	} else if strings.HasPrefix(ref, "@") {
+		return "", HandleErr
+	} else if strings.HasPrefix(ref, "UC") && len(ref) == 24 {
+		return ref, nil
+	}
+
+	channelRequest := s.ytService.Channels.List([]string{"id"})
+	if ref == "mine" {
+		channelRequest = channelRequest.Mine(true)
+	} else {
+		channelRequest = channelRequest.ForUsername(ref)
+	}
+	resp, err := channelRequest.Do()
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Items) == 0 {
+		return "", fmt.Errorf("channel %s not found", ref)
+	}
+	return resp.Items[0].Id, nil
+}
+
+// labelVideos fills in the video titles of the threads.
+func (s *Service) labelVideos(threads []*CommentThread) error {
+	seen := make(map[string]bool)
+	videoIds := make([]string, 0)
+	for _, thread := range threads {
+		if thread.VideoId != "" && !seen[thread.VideoId] {
+			seen[thread.VideoId] = true
+			videoIds = append(videoIds, thread.VideoId)
+		}
+	}
+
+	titles := make(map[string]string)
+	for start := 0; start < len(videoIds); start += channelBatchSize {
+		end := start + channelBatchSize
+		if end > len(videoIds) {
+			end = len(videoIds)
+		}
+		resp, err := s.ytService.Videos.List([]string{"snippet"}).Id(videoIds[start:end]...).MaxResults(int64(channelBatchSize)).Do()
+		if err != nil {
+			return err
+		}
+		for _, video := range resp.Items {
+			titles[video.Id] = video.Snippet.Title
+		}
+	}
+	for _, thread := range threads {
+		thread.VideoTitle = titles[thread.VideoId]
+	}
+	return nil
+}
+
+// LookupChannel returns the ID and title of the channel ref names, in any form
+// ListChannelComments accepts.
+func (s *Service) LookupChannel(ref string) (string, string, error) {
+	channelId, err := s.resolveChannelId(ref)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := s.ytService.Channels.List([]string{"snippet"}).Id(channelId).Do()
+	if err != nil {
+		return "", "", err
+	}
+	if len(resp.Items) == 0 {
+		return "", "", fmt.Errorf("channel %s not found", ref)
+	}
+	return resp.Items[0].Id, resp.Items[0].Snippet.Title, nil
+}
@@ -0,0 +1,19 @@
+package youtube
+
+import "testing"
+
+func TestApiTextFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatPlain, "plainText"},
+		{FormatHtml, "html"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := apiTextFormat(test.format); got != test.want {
+			t.Errorf("apiTextFormat(%q) = %q, want %q", test.format, got, test.want)
+		}
+	}
+}
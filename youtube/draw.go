@@ -0,0 +1,772 @@
+package youtube
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	dataDirName       = ".yt"
+	drawsDirName      = "draws"
+	drawIndexFileName = "draws.index"
+	recordVersion     = 1
+)
+
+const (
+	// AnyVideo enters everyone who commented on at least one video.
+	AnyVideo = "any"
+	// AllVideos only enters people who commented on every video.
+	AllVideos = "all"
+)
+
+var (
+	NotEnoughEntrantsErr = errors.New("not enough entrants for the requested number of winners")
+	CommitmentErr        = errors.New("seed does not match the published commitment")
+	VerifyErr            = errors.New("recomputed winners do not match the record")
+	SnapshotErr          = errors.New("recomputed entrant snapshot does not match the published digest")
+	BeaconErr            = errors.New("beacon does not match the published one")
+	RedrawErr            = errors.New("record has a redraw its rules cannot produce")
+)
+
+// Rules are the settings that decide who can win a draw. They are saved with
+// every draw so it can be recomputed.
+type Rules struct {
+	// Count is the number of winners. With prizes it is the sum of their counts.
+	Count int `json:"count"`
+	// Prizes are drawn in order, the first Prizes[0].Count winners get the
+	// first prize and so on.
+	Prizes []Prize `json:"prizes,omitempty"`
+	// Alternates is how many runners-up to draw after the winners, in order,
+	// for rerolls.
+	Alternates int        `json:"alternates,omitempty"`
+	Entry      EntryRules `json:"entry"`
+	// Mode is AnyVideo or AllVideos and decides whether commenting on one of
+	// the videos is enough to enter. AnyVideo is the default.
+	Mode string `json:"mode,omitempty"`
+	// VideoWeights is the number of tickets a comment on each video is worth,
+	// keyed by video ID. Videos without a weight are worth one ticket. Without
+	// any weights everyone holds one ticket, however many videos they
+	// commented on.
+	VideoWeights map[string]int `json:"videoWeights,omitempty"`
+	// Tickets awards bonus tickets on top of the video weights.
+	Tickets TicketRules `json:"tickets"`
+	// ExcludeOwner keeps the channel that uploaded the videos out of the draw.
+	ExcludeOwner bool `json:"excludeOwner,omitempty"`
+	// Exclusions keep specific channels out of the draw.
+	Exclusions []Exclusion `json:"exclusions,omitempty"`
+	// Channels screens out entrants by the age and activity of their channel.
+	Channels ChannelRules `json:"channels"`
+	// SubscribersOnly redraws winners who are not subscribed to the channel.
+	SubscribersOnly bool `json:"subscribersOnly,omitempty"`
+	// PrivateSubscriptions is RedrawPrivate or AllowPrivate and decides what
+	// happens to winners whose subscriptions cannot be checked.
+	PrivateSubscriptions string `json:"privateSubscriptions,omitempty"`
+}
+
+// Prize is a prize tier of a draw.
+type Prize struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Exclusion keeps a channel out of the draw. Exclusions with the same reason
+// are reported together.
+type Exclusion struct {
+	AuthorChannelId string `json:"authorChannelId"`
+	Reason          string `json:"reason"`
+}
+
+// TicketRules award bonus tickets to a candidate. Every eligible comment adds
+// PerComment, PerLike for each of its likes and PerReply for each reply in its
+// thread. Channels listed in Members get Member extra tickets. When Max is set
+// no candidate holds more than Max tickets.
+type TicketRules struct {
+	PerComment int64    `json:"perComment,omitempty"`
+	PerLike    int64    `json:"perLike,omitempty"`
+	PerReply   int64    `json:"perReply,omitempty"`
+	Member     int64    `json:"member,omitempty"`
+	Members    []string `json:"members,omitempty"`
+	Max        int64    `json:"max,omitempty"`
+}
+
+// Weighted reports whether the rules give anyone more than one ticket.
+func (t TicketRules) Weighted() bool {
+	return t.PerComment > 0 || t.PerLike > 0 || t.PerReply > 0 || t.Member > 0
+}
+
+func (t TicketRules) bonus(candidate *Candidate, members map[string]bool) int64 {
+	var tickets int64
+	for _, entrant := range candidate.Entries {
+		tickets += t.PerComment + t.PerLike*entrant.LikeCount + t.PerReply*entrant.ReplyCount
+	}
+	if members[candidate.AuthorChannelId] {
+		tickets += t.Member
+	}
+	return tickets
+}
+
+// Candidate is one person in the draw pool. People are keyed by their author
+// channel ID, so commenting more than once on a video does not add more
+// chances. Tickets is the candidate's weight in the draw.
+type Candidate struct {
+	AuthorChannelId   string
+	AuthorDisplayName string
+	Entries           []*Entrant
+	Tickets           int64
+	// key is what the candidate is deduplicated and sorted on: the channel ID,
+	// or the comment ID for an entrant without a channel.
+	key string
+}
+
+// DrawReport summarizes how the entrant snapshot became the draw pool.
+type DrawReport struct {
+	Comments   int          `json:"comments"`
+	Excluded   []RuleReport `json:"excluded,omitempty"`
+	Eligible   int          `json:"eligible"`
+	Duplicates int          `json:"duplicates"`
+	// MissingVideos is the number of people excluded for not commenting on
+	// every video in AllVideos mode.
+	MissingVideos int   `json:"missingVideos,omitempty"`
+	Entrants      int   `json:"entrants"`
+	Tickets       int64 `json:"tickets"`
+}
+
+// Redraw is a drawn candidate who was passed over, and why.
+type Redraw struct {
+	AuthorChannelId   string `json:"authorChannelId"`
+	AuthorDisplayName string `json:"authorDisplayName"`
+	Reason            string `json:"reason"`
+	// CommentId is only set for candidates without a channel.
+	CommentId string `json:"commentId,omitempty"`
+}
+
+func (r *Redraw) key() string {
+	if r.AuthorChannelId == "" {
+		return commentKey(r.CommentId)
+	}
+	return r.AuthorChannelId
+}
+
+// EligibilityCheck is asked about every drawn candidate before they become a
+// winner. A non-empty reason disqualifies the candidate and the draw continues.
+type EligibilityCheck func(candidate *Candidate) (reason string, err error)
+
+// Winner is an entrant picked by a draw.
+type Winner struct {
+	CommentId         string `json:"commentId"`
+	AuthorChannelId   string `json:"authorChannelId"`
+	AuthorDisplayName string `json:"authorDisplayName"`
+	Prize             string `json:"prize,omitempty"`
+}
+
+// key identifies the winner in the claims of a record: the channel ID, or the
+// comment ID for a winner without a channel.
+func (w *Winner) key() string {
+	if w.AuthorChannelId == "" {
+		return commentKey(w.CommentId)
+	}
+	return w.AuthorChannelId
+}
+
+func commentKey(commentId string) string {
+	return "comment:" + commentId
+}
+
+// DrawRecord is a self-contained account of a draw: the entrant snapshot, the
+// seed, the rules and the result.
+type DrawRecord struct {
+	Version    int        `json:"version"`
+	Id         string     `json:"id"`
+	Videos     []string   `json:"videos"`
+	ChannelId  string     `json:"channelId,omitempty"`
+	LiveChat   bool       `json:"liveChat,omitempty"`
+	DrawnAt    time.Time  `json:"drawnAt"`
+	Commitment string     `json:"commitment"`
+	Seed       string     `json:"seed"`
+	Rules      Rules      `json:"rules"`
+	Entrants   []*Entrant `json:"entrants"`
+	// SnapshotDigest is the SHA-256 of the published entrant snapshot, if any.
+	SnapshotDigest string `json:"snapshotDigest,omitempty"`
+	// Beacon is a public random value announced after the snapshot digest was
+	// published, mixed into the seed when set.
+	Beacon     string     `json:"beacon,omitempty"`
+	Report     DrawReport `json:"report"`
+	Redraws    []*Redraw  `json:"redraws,omitempty"`
+	Winners    []*Winner  `json:"winners"`
+	Alternates []*Winner  `json:"alternates,omitempty"`
+	Rerolls    []*Reroll  `json:"rerolls,omitempty"`
+	// ClaimDays is how many days winners have to claim their prize, 0 for no
+	// deadline. Claims holds the claim state by winner channel ID, or by
+	// "comment:" and the comment ID for winners without a channel.
+	ClaimDays int               `json:"claimDays,omitempty"`
+	Claims    map[string]*Claim `json:"claims,omitempty"`
+}
+
+// Validate reports rules that can never be applied.
+func (r Rules) Validate() error {
+	if r.Count < 1 {
+		return fmt.Errorf("at least one winner is required")
+	}
+	if r.Alternates < 0 {
+		return fmt.Errorf("alternates cannot be negative")
+	}
+	if r.Channels.MinAgeDays < 0 {
+		return fmt.Errorf("minimum channel age cannot be negative")
+	}
+	if len(r.Prizes) > 0 {
+		total := 0
+		names := make(map[string]bool)
+		for _, prize := range r.Prizes {
+			if prize.Name == "" || prize.Count < 1 {
+				return fmt.Errorf("every prize needs a name and at least one winner")
+			}
+			if names[prize.Name] {
+				return fmt.Errorf("prize %q is listed twice, give it a single tier with the total count", prize.Name)
+			}
+			names[prize.Name] = true
+			total += prize.Count
+		}
+		if total != r.Count {
+			return fmt.Errorf("prizes are for %d winners but the draw has %d", total, r.Count)
+		}
+	}
+	if r.Mode != "" && r.Mode != AnyVideo && r.Mode != AllVideos {
+		return fmt.Errorf("video mode must be %q or %q", AnyVideo, AllVideos)
+	}
+	if r.Tickets.PerComment < 0 || r.Tickets.PerLike < 0 || r.Tickets.PerReply < 0 || r.Tickets.Member < 0 || r.Tickets.Max < 0 {
+		return fmt.Errorf("ticket rules cannot be negative")
+	}
+	for videoId, weight := range r.VideoWeights {
+		if weight < 1 {
+			return fmt.Errorf("video %s must be worth at least one ticket", videoId)
+		}
+	}
+	if r.PrivateSubscriptions != "" && r.PrivateSubscriptions != RedrawPrivate && r.PrivateSubscriptions != AllowPrivate {
+		return fmt.Errorf("private subscription policy must be %q or %q", RedrawPrivate, AllowPrivate)
+	}
+	return r.Entry.Validate()
+}
+
+// NewSeed returns 32 bytes from crypto/rand encoded as hex.
+func NewSeed() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Commitment is the SHA-256 of seed. Publishing it before a draw proves the
+// seed was chosen before the entrants were known.
+func Commitment(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// drawSeed is what the winners are derived from. Without a beacon it is the
+// seed itself. With one it is the SHA-256 of the seed, the snapshot digest and
+// the beacon, joined by newlines, so whoever knows the seed still cannot tell
+// who wins until the beacon is out, and by then the entrants are fixed.
+func (r *DrawRecord) drawSeed() string {
+	if r.Beacon == "" {
+		return r.Seed
+	}
+	sum := sha256.Sum256([]byte(r.Seed + "\n" + r.SnapshotDigest + "\n" + r.Beacon))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewDrawRecord(videos []string, entrants []*Entrant, rules Rules, seed string) *DrawRecord {
+	drawnAt := time.Now().UTC()
+	return &DrawRecord{
+		Version:    recordVersion,
+		Id:         newDrawId(drawnAt),
+		Videos:     videos,
+		DrawnAt:    drawnAt,
+		Commitment: Commitment(seed),
+		Seed:       seed,
+		Rules:      rules,
+		Entrants:   entrants,
+	}
+}
+
+// newDrawId is the draw time followed by a random suffix, so draws made in
+// the same second still get their own record.
+func newDrawId(drawnAt time.Time) string {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		binary.BigEndian.PutUint16(suffix, uint16(drawnAt.Nanosecond()))
+	}
+	return drawnAt.Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// Run draws the winners and alternates and stores them on the record. check
+// may be nil.
+func (r *DrawRecord) Run(check EligibilityCheck) error {
+	drawn, redraws, report, err := r.draw(check)
+	r.Report = report
+	r.Redraws = redraws
+	if err != nil {
+		return err
+	}
+	r.Winners = drawn[:r.Rules.Count]
+	r.Alternates = drawn[r.Rules.Count:]
+	r.Rerolls = nil
+	return nil
+}
+
+// prize returns the name of the prize for the i-th winner, or an empty string
+// when the draw has no prizes or i is an alternate.
+func (r *DrawRecord) prize(i int) string {
+	for _, prize := range r.Rules.Prizes {
+		if i < prize.Count {
+			return prize.Name
+		}
+		i -= prize.Count
+	}
+	return ""
+}
+
+// Published holds the values announced publicly around a draw, as opposed to
+// the ones read from the record. A record can be rewritten wholesale, so only
+// these prove anything about it.
+type Published struct {
+	Commitment     string
+	SnapshotDigest string
+	Beacon         string
+}
+
+// Verify checks the seed, snapshot digest and beacon against the published
+// values and recomputes the winners and alternates from the entrant snapshot,
+// then replays the rerolls. Published values left empty are not checked, and
+// without any the record is only checked for being consistent with itself.
+// It needs no network access: eligibility checks are replayed from the
+// redraws in the record. Their reasons cannot be checked, only that the rules
+// allow them.
+func (r *DrawRecord) Verify(published Published) error {
+	if r.Commitment != Commitment(r.Seed) {
+		return CommitmentErr
+	}
+	if published.Commitment != "" && !strings.EqualFold(published.Commitment, r.Commitment) {
+		return CommitmentErr
+	}
+	if published.SnapshotDigest != "" && !strings.EqualFold(published.SnapshotDigest, r.SnapshotDigest) {
+		return SnapshotErr
+	}
+	if published.Beacon != "" && published.Beacon != r.Beacon {
+		return BeaconErr
+	}
+	reasons := make(map[string]string)
+	for _, redraw := range r.Redraws {
+		if err := r.Rules.redrawReasonErr(redraw.Reason); err != nil {
+			return err
+		}
+		reasons[redraw.key()] = redraw.Reason
+	}
+	replay := func(candidate *Candidate) (string, error) {
+		return reasons[candidate.key], nil
+	}
+
+	if r.SnapshotDigest != "" {
+		pool, _, err := r.Pool()
+		if err != nil {
+			return err
+		}
+		digest, err := r.EntrantSnapshotDigest(pool)
+		if err != nil {
+			return err
+		}
+		if digest != r.SnapshotDigest {
+			return SnapshotErr
+		}
+	}
+
+	drawn, redraws, _, err := r.draw(replay)
+	if err != nil {
+		return err
+	}
+	if len(redraws) != len(r.Redraws) {
+		return VerifyErr
+	}
+	for i, redraw := range redraws {
+		if *redraw != *r.Redraws[i] {
+			return VerifyErr
+		}
+	}
+
+	replayed := &DrawRecord{
+		Winners:    drawn[:r.Rules.Count],
+		Alternates: drawn[r.Rules.Count:],
+	}
+	for _, reroll := range r.Rerolls {
+		promoted, err := replayed.promote(reroll.Disqualified.CommentId)
+		if err != nil || *promoted != *reroll.Promoted {
+			return VerifyErr
+		}
+	}
+	if !sameWinners(replayed.Winners, r.Winners) || !sameWinners(replayed.Alternates, r.Alternates) {
+		return VerifyErr
+	}
+	return nil
+}
+
+func sameWinners(a, b []*Winner) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if *a[i] != *b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Pool applies the entry rules to the entrant snapshot and groups the eligible
+// comments into candidates, one per author channel, sorted by channel ID so the
+// result does not depend on the order the API returned the comments in. Each
+// candidate gets the weight of every distinct video they commented on plus
+// any bonus tickets, up to the ticket cap.
+func (r *DrawRecord) Pool() ([]*Candidate, DrawReport, error) {
+	filters, err := r.Rules.Entry.filters()
+	if err != nil {
+		return nil, DrawReport{}, err
+	}
+	filters = append(filters, r.exclusionFilters()...)
+	eligible, excluded := applyFilters(r.Entrants, filters)
+
+	byChannel := make(map[string]*Candidate)
+	pool := make([]*Candidate, 0)
+	for _, entrant := range eligible {
+		key := entrant.AuthorChannelId
+		if key == "" {
+			// without a channel there is nothing to deduplicate on
+			key = commentKey(entrant.CommentId)
+		}
+		candidate, ok := byChannel[key]
+		if !ok {
+			candidate = &Candidate{
+				AuthorChannelId:   entrant.AuthorChannelId,
+				AuthorDisplayName: entrant.AuthorDisplayName,
+				key:               key,
+			}
+			byChannel[key] = candidate
+			pool = append(pool, candidate)
+		}
+		candidate.Entries = append(candidate.Entries, entrant)
+	}
+
+	sort.Slice(pool, func(i, j int) bool {
+		return pool[i].key < pool[j].key
+	})
+
+	report := DrawReport{
+		Comments:   len(r.Entrants),
+		Excluded:   excluded,
+		Eligible:   len(eligible),
+		Duplicates: len(eligible) - len(pool),
+	}
+
+	members := make(map[string]bool)
+	for _, member := range r.Rules.Tickets.Members {
+		members[member] = true
+	}
+
+	entered := make([]*Candidate, 0, len(pool))
+	for _, candidate := range pool {
+		videos := make(map[string]bool)
+		for _, entrant := range candidate.Entries {
+			if !videos[entrant.VideoId] {
+				videos[entrant.VideoId] = true
+				candidate.Tickets += int64(r.videoWeight(entrant.VideoId))
+			}
+		}
+		if len(r.Rules.VideoWeights) == 0 {
+			candidate.Tickets = 1
+		}
+		if r.Rules.Mode == AllVideos && len(videos) < len(r.Videos) {
+			report.MissingVideos++
+			continue
+		}
+		candidate.Tickets += r.Rules.Tickets.bonus(candidate, members)
+		if r.Rules.Tickets.Max > 0 && candidate.Tickets > r.Rules.Tickets.Max {
+			candidate.Tickets = r.Rules.Tickets.Max
+		}
+		entered = append(entered, candidate)
+		report.Tickets += candidate.Tickets
+	}
+	pool = entered
+	report.Entrants = len(pool)
+
+	for _, candidate := range pool {
+		sort.Slice(candidate.Entries, func(i, j int) bool {
+			a, b := candidate.Entries[i], candidate.Entries[j]
+			if !a.PublishedAt.Equal(b.PublishedAt) {
+				return a.PublishedAt.Before(b.PublishedAt)
+			}
+			return a.CommentId < b.CommentId
+		})
+	}
+
+	return pool, report, nil
+}
+
+// exclusionFilters turns the excluded channels into one filter per reason, in
+// the order the reasons first appear.
+func (r *DrawRecord) exclusionFilters() []entryFilter {
+	exclusions := r.Rules.Exclusions
+	if r.Rules.ExcludeOwner && r.ChannelId != "" {
+		exclusions = append([]Exclusion{{AuthorChannelId: r.ChannelId, Reason: "channel owner"}}, exclusions...)
+	}
+
+	reasons := make([]string, 0)
+	byReason := make(map[string]map[string]bool)
+	for _, exclusion := range exclusions {
+		channels, ok := byReason[exclusion.Reason]
+		if !ok {
+			channels = make(map[string]bool)
+			byReason[exclusion.Reason] = channels
+			reasons = append(reasons, exclusion.Reason)
+		}
+		channels[exclusion.AuthorChannelId] = true
+	}
+
+	filters := make([]entryFilter, 0, len(reasons))
+	for _, reason := range reasons {
+		channels := byReason[reason]
+		filters = append(filters, entryFilter{
+			name: reason,
+			keep: func(entrant *Entrant) bool {
+				return !channels[entrant.AuthorChannelId]
+			},
+		})
+	}
+	return filters
+}
+
+func (r *DrawRecord) videoWeight(videoId string) int {
+	if weight, ok := r.Rules.VideoWeights[videoId]; ok {
+		return weight
+	}
+	return 1
+}
+
+// draw picks the winners followed by the alternates.
+func (r *DrawRecord) draw(check EligibilityCheck) ([]*Winner, []*Redraw, DrawReport, error) {
+	pool, report, err := r.Pool()
+	if err != nil {
+		return nil, nil, report, err
+	}
+	if r.Rules.Count > len(pool) {
+		return nil, nil, report, fmt.Errorf("%w: %d unique entrants, %d winners", NotEnoughEntrantsErr, len(pool), r.Rules.Count)
+	}
+
+	rnd := newDrawRand(r.drawSeed())
+	total := r.Rules.Count + r.Rules.Alternates
+	winners := make([]*Winner, 0, total)
+	redraws := make([]*Redraw, 0)
+	for len(winners) < total {
+		if len(pool) == 0 {
+			if len(winners) >= r.Rules.Count {
+				// fewer alternates than asked for is fine
+				break
+			}
+			return nil, redraws, report, fmt.Errorf("%w: only %d of %d winners were eligible", NotEnoughEntrantsErr, len(winners), r.Rules.Count)
+		}
+		i := pickTicket(pool, rnd.int63n(totalTickets(pool)))
+		candidate := pool[i]
+		pool = append(pool[:i], pool[i+1:]...)
+
+		if check != nil {
+			reason, err := check(candidate)
+			if err != nil {
+				return nil, redraws, report, err
+			}
+			if reason != "" {
+				redraw := &Redraw{
+					AuthorChannelId:   candidate.AuthorChannelId,
+					AuthorDisplayName: candidate.AuthorDisplayName,
+					Reason:            reason,
+				}
+				if candidate.AuthorChannelId == "" {
+					redraw.CommentId = candidate.Entries[0].CommentId
+				}
+				redraws = append(redraws, redraw)
+				continue
+			}
+		}
+
+		winners = append(winners, &Winner{
+			CommentId:         candidate.Entries[0].CommentId,
+			AuthorChannelId:   candidate.AuthorChannelId,
+			AuthorDisplayName: candidate.AuthorDisplayName,
+			Prize:             r.prize(len(winners)),
+		})
+	}
+	return winners, redraws, report, nil
+}
+
+func totalTickets(pool []*Candidate) int64 {
+	var total int64
+	for _, candidate := range pool {
+		total += candidate.Tickets
+	}
+	return total
+}
+
+// pickTicket returns the index of the candidate holding ticket, counting the
+// tickets of the candidates in pool order.
+func pickTicket(pool []*Candidate, ticket int64) int {
+	for i, candidate := range pool {
+		if ticket < candidate.Tickets {
+			return i
+		}
+		ticket -= candidate.Tickets
+	}
+	return len(pool) - 1
+}
+
+// DrawsDir is where draw records are saved by default.
+func DrawsDir() string {
+	return filepath.Join(home, dataDirName, drawsDirName)
+}
+
+// Save writes the record as JSON to path, or to DrawsDir when path is empty,
+// and returns the path written.
+func (r *DrawRecord) Save(path string) (string, error) {
+	return r.write(path, os.O_TRUNC)
+}
+
+// Create saves a new record like Save, but fails rather than overwrite an
+// existing record. Records saved outside DrawsDir are added to the draw index
+// so they are still found by ID and listed with the others.
+func (r *DrawRecord) Create(path string) (string, error) {
+	path, err := r.write(path, os.O_EXCL)
+	if err != nil {
+		return "", err
+	}
+	if abs, err := filepath.Abs(path); err == nil && filepath.Dir(abs) != DrawsDir() {
+		return path, indexDrawRecord(r.Id, abs)
+	}
+	return path, nil
+}
+
+// DrawIndexPath lists the draw records saved outside DrawsDir, one ID and
+// path per line, separated by a tab.
+func DrawIndexPath() string {
+	return filepath.Join(home, dataDirName, drawIndexFileName)
+}
+
+func indexDrawRecord(id string, path string) error {
+	if err := os.MkdirAll(filepath.Dir(DrawIndexPath()), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(DrawIndexPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(file, "%s\t%s\n", id, path); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readDrawIndex returns the paths of the records saved outside DrawsDir by
+// ID. A missing index is empty.
+func readDrawIndex() (map[string]string, error) {
+	index := make(map[string]string)
+	data, err := ioutil.ReadFile(DrawIndexPath())
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.SplitN(line, "\t", 2); len(fields) == 2 {
+			index[fields[0]] = fields[1]
+		}
+	}
+	return index, nil
+}
+
+func (r *DrawRecord) write(path string, flag int) (string, error) {
+	if path == "" {
+		path = filepath.Join(DrawsDir(), r.Id+".json")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|flag, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return "", err
+	}
+	return path, file.Close()
+}
+
+func ReadDrawRecord(path string) (*DrawRecord, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	record := new(DrawRecord)
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return record, nil
+}
+
+// drawRand is a deterministic random stream. Value n is the first 8 bytes of
+// SHA-256(seed || n) read as a big-endian integer, so a draw can be replayed
+// by anyone who knows the seed, in any language.
+type drawRand struct {
+	seed    []byte
+	counter uint64
+}
+
+func newDrawRand(seed string) *drawRand {
+	return &drawRand{seed: []byte(seed)}
+}
+
+func (d *drawRand) uint64() uint64 {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], d.counter)
+	d.counter++
+
+	h := sha256.New()
+	h.Write(d.seed)
+	h.Write(counter[:])
+	return binary.BigEndian.Uint64(h.Sum(nil)[:8])
+}
+
+// int63n returns a uniform value in [0, n), rejecting values from the
+// incomplete range at the top to avoid modulo bias.
+func (d *drawRand) int63n(n int64) int64 {
+	limit := math.MaxUint64 - math.MaxUint64%uint64(n)
+	for {
+		v := d.uint64()
+		if v < limit {
+			return int64(v % uint64(n))
+		}
+	}
+}
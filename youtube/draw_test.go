@@ -0,0 +1,530 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDrawRand(t *testing.T) {
+	// first 8 bytes of SHA-256("seed" || n), n as a big-endian uint64
+	want := []uint64{0x1a30d3c0635d49b5, 0x72e4f9aecea933bf, 0xe949d45204b2eee8}
+	rnd := newDrawRand("seed")
+	for i, w := range want {
+		if got := rnd.uint64(); got != w {
+			t.Errorf("value %d = %#x, want %#x", i, got, w)
+		}
+	}
+}
+
+func TestDrawRandInt63n(t *testing.T) {
+	rnd := newDrawRand("seed")
+	for _, n := range []int64{1, 2, 3, 7, 1000, 1 << 62} {
+		for i := 0; i < 100; i++ {
+			if v := rnd.int63n(n); v < 0 || v >= n {
+				t.Fatalf("int63n(%d) = %d, out of range", n, v)
+			}
+		}
+	}
+}
+
+func TestPickTicket(t *testing.T) {
+	pool := []*Candidate{{Tickets: 1}, {Tickets: 3}, {Tickets: 2}}
+	tests := []struct {
+		ticket int64
+		want   int
+	}{
+		{0, 0},
+		{1, 1},
+		{3, 1},
+		{4, 2},
+		{5, 2},
+	}
+	for _, test := range tests {
+		if got := pickTicket(pool, test.ticket); got != test.want {
+			t.Errorf("pickTicket(%d) = %d, want %d", test.ticket, got, test.want)
+		}
+	}
+}
+
+func testEntrants() []*Entrant {
+	publishedAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	entrants := make([]*Entrant, 0)
+	for i := 0; i < 10; i++ {
+		entrants = append(entrants, &Entrant{
+			CommentId:         fmt.Sprintf("comment%d", i),
+			VideoId:           "video",
+			AuthorChannelId:   fmt.Sprintf("UC%d", i),
+			AuthorDisplayName: fmt.Sprintf("user%d", i),
+			Text:              "count me in",
+			PublishedAt:       publishedAt.Add(time.Duration(i) * time.Minute),
+			UpdatedAt:         publishedAt.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	// a second comment by the same person does not add a ticket
+	entrants = append(entrants, &Entrant{
+		CommentId:         "comment10",
+		VideoId:           "video",
+		AuthorChannelId:   "UC3",
+		AuthorDisplayName: "user3",
+		Text:              "again",
+		PublishedAt:       publishedAt.Add(time.Hour),
+		UpdatedAt:         publishedAt.Add(time.Hour),
+	})
+	return entrants
+}
+
+func testRecord(t *testing.T) *DrawRecord {
+	record := NewDrawRecord([]string{"video"}, testEntrants(), Rules{Count: 2, Alternates: 2}, "seed")
+	if err := record.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+func winnerNames(winners []*Winner) []string {
+	names := make([]string, len(winners))
+	for i, winner := range winners {
+		names[i] = winner.AuthorDisplayName
+	}
+	return names
+}
+
+func TestDraw(t *testing.T) {
+	tests := []struct {
+		name       string
+		beacon     string
+		winners    []string
+		alternates []string
+	}{
+		{"seed", "", []string{"user3", "user2"}, []string{"user0", "user5"}},
+		{"beacon", "beacon", []string{"user5", "user3"}, []string{"user1", "user4"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			record := NewDrawRecord([]string{"video"}, testEntrants(), Rules{Count: 2, Alternates: 2}, "seed")
+			record.Beacon = test.beacon
+			if err := record.Run(nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := winnerNames(record.Winners); fmt.Sprint(got) != fmt.Sprint(test.winners) {
+				t.Errorf("winners = %v, want %v", got, test.winners)
+			}
+			if got := winnerNames(record.Alternates); fmt.Sprint(got) != fmt.Sprint(test.alternates) {
+				t.Errorf("alternates = %v, want %v", got, test.alternates)
+			}
+		})
+	}
+}
+
+func TestDrawOrderIndependent(t *testing.T) {
+	entrants := testEntrants()
+	reversed := make([]*Entrant, len(entrants))
+	for i, entrant := range entrants {
+		reversed[len(entrants)-1-i] = entrant
+	}
+	a := NewDrawRecord([]string{"video"}, entrants, Rules{Count: 3}, "seed")
+	b := NewDrawRecord([]string{"video"}, reversed, Rules{Count: 3}, "seed")
+	if err := a.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+	if !sameWinners(a.Winners, b.Winners) {
+		t.Errorf("winners depend on entrant order: %v and %v", winnerNames(a.Winners), winnerNames(b.Winners))
+	}
+}
+
+func TestDrawNotEnoughEntrants(t *testing.T) {
+	record := NewDrawRecord([]string{"video"}, testEntrants(), Rules{Count: 11}, "seed")
+	if err := record.Run(nil); err == nil {
+		t.Error("drew 11 winners from 10 entrants")
+	}
+}
+
+func TestDrawRedraws(t *testing.T) {
+	record := NewDrawRecord([]string{"video"}, testEntrants(), Rules{Count: 2, SubscribersOnly: true}, "seed")
+	check := func(candidate *Candidate) (string, error) {
+		if candidate.AuthorChannelId == "UC3" {
+			return notSubscribedReason, nil
+		}
+		return "", nil
+	}
+	if err := record.Run(check); err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Redraws) != 1 || record.Redraws[0].AuthorChannelId != "UC3" {
+		t.Fatalf("redraws = %v, want UC3", record.Redraws)
+	}
+	for _, winner := range record.Winners {
+		if winner.AuthorChannelId == "UC3" {
+			t.Error("redrawn candidate won")
+		}
+	}
+	if err := record.Verify(Published{}); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		tamper    func(r *DrawRecord)
+		published Published
+		wantErr   error
+	}{
+		{"untouched", func(r *DrawRecord) {}, Published{}, nil},
+		{"published commitment", func(r *DrawRecord) {}, Published{Commitment: Commitment("seed")}, nil},
+		{"other published commitment", func(r *DrawRecord) {}, Published{Commitment: Commitment("other")}, CommitmentErr},
+		{"seed", func(r *DrawRecord) { r.Seed = "other" }, Published{}, CommitmentErr},
+		{"seed and commitment", func(r *DrawRecord) {
+			r.Seed = "other"
+			r.Commitment = Commitment("other")
+		}, Published{Commitment: Commitment("seed")}, CommitmentErr},
+		{"winner", func(r *DrawRecord) { r.Winners[0].AuthorDisplayName = "someone" }, Published{}, VerifyErr},
+		{"winner order", func(r *DrawRecord) {
+			r.Winners[0], r.Winners[1] = r.Winners[1], r.Winners[0]
+		}, Published{}, VerifyErr},
+		{"alternate", func(r *DrawRecord) { r.Alternates = r.Alternates[:1] }, Published{}, VerifyErr},
+		{"entrants", func(r *DrawRecord) { r.Entrants = r.Entrants[1:] }, Published{}, VerifyErr},
+		{"redraws", func(r *DrawRecord) {
+			r.Redraws = append(r.Redraws, &Redraw{AuthorChannelId: "UC0", Reason: notSubscribedReason})
+		}, Published{}, RedrawErr},
+		{"redrawn winner", func(r *DrawRecord) {
+			// a consistent record, but the rules never checked subscriptions
+			redrawn := r.Winners[0].AuthorChannelId
+			r.Winners, r.Alternates, r.Redraws = nil, nil, nil
+			if err := r.Run(func(candidate *Candidate) (string, error) {
+				if candidate.AuthorChannelId == redrawn {
+					return notSubscribedReason, nil
+				}
+				return "", nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}, Published{Commitment: Commitment("seed")}, RedrawErr},
+		{"redraw reason", func(r *DrawRecord) {
+			r.Rules.SubscribersOnly = true
+			r.Redraws = append(r.Redraws, &Redraw{AuthorChannelId: "UC0", Reason: "made up"})
+		}, Published{}, RedrawErr},
+		{"private redraw", func(r *DrawRecord) {
+			r.Rules.SubscribersOnly = true
+			r.Rules.PrivateSubscriptions = AllowPrivate
+			r.Redraws = append(r.Redraws, &Redraw{AuthorChannelId: "UC0", Reason: privateReason})
+		}, Published{}, RedrawErr},
+		{"beacon", func(r *DrawRecord) { r.Beacon = "beacon" }, Published{}, VerifyErr},
+		{"published beacon", func(r *DrawRecord) {}, Published{Beacon: "beacon"}, BeaconErr},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			record := testRecord(t)
+			test.tamper(record)
+			if err := record.Verify(test.published); !errors.Is(err, test.wantErr) {
+				t.Errorf("Verify() = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifySnapshot(t *testing.T) {
+	record := NewDrawRecord([]string{"video"}, testEntrants(), Rules{Count: 2}, "seed")
+	pool, _, err := record.Pool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.SnapshotDigest, err = record.EntrantSnapshotDigest(pool); err != nil {
+		t.Fatal(err)
+	}
+	record.Beacon = "beacon"
+	if err := record.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+	digest := record.SnapshotDigest
+	published := Published{Commitment: Commitment("seed"), SnapshotDigest: digest, Beacon: "beacon"}
+	if err := record.Verify(published); err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+
+	record.Entrants[0].AuthorDisplayName = "edited"
+	if err := record.Verify(published); err != SnapshotErr {
+		t.Errorf("Verify() with edited entrants = %v, want %v", err, SnapshotErr)
+	}
+	record.Entrants[0].AuthorDisplayName = "user0"
+	published.SnapshotDigest = Commitment("other")
+	if err := record.Verify(published); err != SnapshotErr {
+		t.Errorf("Verify() with another published digest = %v, want %v", err, SnapshotErr)
+	}
+}
+
+func TestPoolWithoutChannel(t *testing.T) {
+	publishedAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	entrants := []*Entrant{
+		{CommentId: "c1", AuthorDisplayName: "one", PublishedAt: publishedAt},
+		{CommentId: "c2", AuthorDisplayName: "two", PublishedAt: publishedAt},
+		{CommentId: "c3", AuthorChannelId: "UC3", AuthorDisplayName: "three", PublishedAt: publishedAt},
+		{CommentId: "c4", AuthorChannelId: "UC4", AuthorDisplayName: "four", PublishedAt: publishedAt},
+	}
+	record := NewDrawRecord(nil, entrants, Rules{Count: 2, Alternates: 1, SubscribersOnly: true}, "seed")
+	pool, _, err := record.Pool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pool) != 4 {
+		t.Fatalf("pool has %d candidates, want 4", len(pool))
+	}
+	for _, candidate := range pool {
+		if candidate.AuthorChannelId != candidate.Entries[0].AuthorChannelId {
+			t.Errorf("candidate channel = %q, want %q", candidate.AuthorChannelId, candidate.Entries[0].AuthorChannelId)
+		}
+	}
+
+	check := func(candidate *Candidate) (string, error) {
+		if candidate.AuthorChannelId == "" && candidate.Entries[0].CommentId == "c1" {
+			return noChannelReason, nil
+		}
+		return "", nil
+	}
+	if err := record.Run(check); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := record.Reroll(record.Winners[0].CommentId, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := record.Verify(Published{}); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		ok    bool
+	}{
+		{"one winner", Rules{Count: 1}, true},
+		{"no winners", Rules{Count: 0}, false},
+		{"prizes", Rules{Count: 3, Prizes: []Prize{{"Grand prize", 1}, {"Runner-up", 2}}}, true},
+		{"prize count", Rules{Count: 2, Prizes: []Prize{{"Grand prize", 1}, {"Runner-up", 2}}}, false},
+		{"unnamed prize", Rules{Count: 1, Prizes: []Prize{{"", 1}}}, false},
+		{"duplicate prize", Rules{Count: 2, Prizes: []Prize{{"Sticker", 1}, {"Sticker", 1}}}, false},
+		{"mode", Rules{Count: 1, Mode: "some"}, false},
+		{"video weight", Rules{Count: 1, VideoWeights: map[string]int{"video": 0}}, false},
+		{"negative tickets", Rules{Count: 1, Tickets: TicketRules{PerLike: -1}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.rules.Validate(); (err == nil) != test.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, test.ok)
+			}
+		})
+	}
+}
+
+func TestPoolVideoTickets(t *testing.T) {
+	publishedAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	entrants := []*Entrant{
+		{CommentId: "c1", VideoId: "v1", AuthorChannelId: "UC1", AuthorDisplayName: "both", PublishedAt: publishedAt},
+		{CommentId: "c2", VideoId: "v2", AuthorChannelId: "UC1", AuthorDisplayName: "both", PublishedAt: publishedAt},
+		{CommentId: "c3", VideoId: "v2", AuthorChannelId: "UC1", AuthorDisplayName: "both", PublishedAt: publishedAt},
+		{CommentId: "c4", VideoId: "v1", AuthorChannelId: "UC2", AuthorDisplayName: "first", PublishedAt: publishedAt},
+		{CommentId: "c5", VideoId: "v2", AuthorChannelId: "UC3", AuthorDisplayName: "second", PublishedAt: publishedAt},
+	}
+	tests := []struct {
+		name    string
+		weights map[string]int
+		mode    string
+		want    map[string]int64
+	}{
+		{"any", nil, AnyVideo, map[string]int64{"UC1": 1, "UC2": 1, "UC3": 1}},
+		{"all", nil, AllVideos, map[string]int64{"UC1": 1}},
+		{"weighted", map[string]int{"v1": 3}, AnyVideo, map[string]int64{"UC1": 4, "UC2": 3, "UC3": 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			record := NewDrawRecord([]string{"v1", "v2"}, entrants, Rules{Count: 1, Mode: test.mode, VideoWeights: test.weights}, "seed")
+			pool, report, err := record.Pool()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]int64)
+			for _, candidate := range pool {
+				got[candidate.AuthorChannelId] = candidate.Tickets
+			}
+			if fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("tickets = %v, want %v", got, test.want)
+			}
+			var tickets int64
+			for _, n := range test.want {
+				tickets += n
+			}
+			if report.Tickets != tickets {
+				t.Errorf("report.Tickets = %d, want %d", report.Tickets, tickets)
+			}
+		})
+	}
+}
+
+func TestTicketRulesWeighted(t *testing.T) {
+	tests := []struct {
+		rules TicketRules
+		want  bool
+	}{
+		{TicketRules{}, false},
+		{TicketRules{Max: 5}, false},
+		{TicketRules{Members: []string{"UC1"}}, false},
+		{TicketRules{PerComment: 1}, true},
+		{TicketRules{PerLike: 1}, true},
+		{TicketRules{PerReply: 1}, true},
+		{TicketRules{Member: 1}, true},
+	}
+	for _, test := range tests {
+		if got := test.rules.Weighted(); got != test.want {
+			t.Errorf("%+v.Weighted() = %v, want %v", test.rules, got, test.want)
+		}
+	}
+}
+
+func TestPoolTickets(t *testing.T) {
+	publishedAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	entrants := []*Entrant{
+		{CommentId: "c1", AuthorChannelId: "UC1", AuthorDisplayName: "liked", LikeCount: 3, PublishedAt: publishedAt},
+		{CommentId: "c2", AuthorChannelId: "UC1", AuthorDisplayName: "liked", LikeCount: 1, ReplyCount: 2, PublishedAt: publishedAt},
+		{CommentId: "c3", AuthorChannelId: "UC2", AuthorDisplayName: "member", PublishedAt: publishedAt},
+		{CommentId: "c4", AuthorChannelId: "UC3", AuthorDisplayName: "viral", LikeCount: 100, PublishedAt: publishedAt},
+		{CommentId: "c5", AuthorChannelId: "UC4", AuthorDisplayName: "plain", PublishedAt: publishedAt},
+	}
+	tickets := TicketRules{PerComment: 1, PerLike: 1, PerReply: 2, Member: 5, Members: []string{"UC2"}, Max: 20}
+	record := NewDrawRecord([]string{"video"}, entrants, Rules{Count: 1, Tickets: tickets}, "seed")
+	pool, report, err := record.Pool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int64)
+	for _, candidate := range pool {
+		got[candidate.AuthorChannelId] = candidate.Tickets
+	}
+	want := map[string]int64{
+		"UC1": 1 + 2*1 + 4*1 + 2*2, // entry, two comments, four likes, two replies
+		"UC2": 1 + 1 + 5,           // entry, one comment, member
+		"UC3": 20,                  // 1 + 1 + 100 likes, capped
+		"UC4": 1 + 1,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("tickets = %v, want %v", got, want)
+	}
+	if report.Tickets != 11+7+20+2 {
+		t.Errorf("report.Tickets = %d, want %d", report.Tickets, 11+7+20+2)
+	}
+}
+
+func TestPoolExclusions(t *testing.T) {
+	publishedAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	entrant := func(comment string, channel string) *Entrant {
+		return &Entrant{CommentId: comment, AuthorChannelId: channel, AuthorDisplayName: channel, PublishedAt: publishedAt}
+	}
+	entrants := []*Entrant{
+		entrant("c1", "UCowner"), entrant("c2", "UCowner"),
+		entrant("c3", "UCmod1"),
+		entrant("c4", "UCmod2"), entrant("c5", "UCmod2"),
+		entrant("c6", "UCwin"),
+		entrant("c7", "UC1"), entrant("c8", "UC2"),
+	}
+	recent := "won in the last 30 days"
+	exclusions := []Exclusion{
+		{AuthorChannelId: "UCmod1", Reason: "moderator"},
+		{AuthorChannelId: "UCwin", Reason: recent},
+		{AuthorChannelId: "UCmod2", Reason: "moderator"},
+		{AuthorChannelId: "UCmod1", Reason: recent},
+	}
+	tests := []struct {
+		name         string
+		excludeOwner bool
+		channelId    string
+		excluded     []RuleReport
+		pool         []string
+	}{
+		{"owner", true, "UCowner", []RuleReport{
+			{Rule: "channel owner", Excluded: 2, People: 1},
+			{Rule: "moderator", Excluded: 3, People: 2},
+			{Rule: recent, Excluded: 2, People: 2},
+		}, []string{"UC1", "UC2"}},
+		{"owner kept", false, "UCowner", []RuleReport{
+			{Rule: "moderator", Excluded: 3, People: 2},
+			{Rule: recent, Excluded: 2, People: 2},
+		}, []string{"UC1", "UC2", "UCowner"}},
+		{"owner unknown", true, "", []RuleReport{
+			{Rule: "moderator", Excluded: 3, People: 2},
+			{Rule: recent, Excluded: 2, People: 2},
+		}, []string{"UC1", "UC2", "UCowner"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules := Rules{Count: 1, ExcludeOwner: test.excludeOwner, Exclusions: exclusions}
+			record := NewDrawRecord([]string{"video"}, entrants, rules, "seed")
+			record.ChannelId = test.channelId
+			pool, report, err := record.Pool()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(report.Excluded) != fmt.Sprint(test.excluded) {
+				t.Errorf("excluded = %v, want %v", report.Excluded, test.excluded)
+			}
+			got := make([]string, 0, len(pool))
+			for _, candidate := range pool {
+				got = append(got, candidate.AuthorChannelId)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(test.pool) {
+				t.Errorf("pool = %v, want %v", got, test.pool)
+			}
+			if report.Entrants != len(test.pool) {
+				t.Errorf("report.Entrants = %d, want %d", report.Entrants, len(test.pool))
+			}
+		})
+	}
+}
+
+func TestDrawPrizes(t *testing.T) {
+	rules := Rules{
+		Count:      3,
+		Prizes:     []Prize{{Name: "Grand prize", Count: 1}, {Name: "Runner-up", Count: 2}},
+		Alternates: 2,
+	}
+	record := NewDrawRecord([]string{"video"}, testEntrants(), rules, "seed")
+	if err := record.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+	prizes := func(winners []*Winner) []string {
+		names := make([]string, 0, len(winners))
+		for _, winner := range winners {
+			names = append(names, winner.Prize)
+		}
+		return names
+	}
+	if got := fmt.Sprint(prizes(record.Winners)); got != "[Grand prize Runner-up Runner-up]" {
+		t.Errorf("winner prizes = %s, want the tiers in order", got)
+	}
+	if got := fmt.Sprint(prizes(record.Alternates)); got != "[ ]" {
+		t.Errorf("alternate prizes = %s, want none", got)
+	}
+
+	// a promoted alternate takes over the prize of the winner they replace
+	grand, runnerUp := record.Winners[0], record.Winners[2]
+	first, second := record.Alternates[0], record.Alternates[1]
+	if _, err := record.RerollWinners([]string{grand.CommentId, runnerUp.CommentId}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if record.Winners[0].CommentId != first.CommentId || record.Winners[0].Prize != "Grand prize" {
+		t.Errorf("winner #1 = %s with %q, want %s with the grand prize", record.Winners[0].AuthorDisplayName, record.Winners[0].Prize, first.AuthorDisplayName)
+	}
+	if record.Winners[2].CommentId != second.CommentId || record.Winners[2].Prize != "Runner-up" {
+		t.Errorf("winner #3 = %s with %q, want %s as runner-up", record.Winners[2].AuthorDisplayName, record.Winners[2].Prize, second.AuthorDisplayName)
+	}
+	if got := fmt.Sprint(prizes(record.Winners)); got != "[Grand prize Runner-up Runner-up]" {
+		t.Errorf("winner prizes after rerolls = %s, want the tiers in order", got)
+	}
+	if err := record.Verify(Published{}); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+}
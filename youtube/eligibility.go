@@ -0,0 +1,91 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// RedrawPrivate redraws winners whose subscriptions are private.
+	RedrawPrivate = "redraw"
+	// AllowPrivate lets winners with private subscriptions win.
+	AllowPrivate = "allow"
+)
+
+// The reasons SubscriberCheck gives, the only ones a record can redraw for.
+const (
+	noChannelReason     = "no channel to check the subscription of"
+	privateReason       = "subscriptions are private"
+	notSubscribedReason = "not subscribed"
+)
+
+// SubscriberCheck disqualifies candidates who are not subscribed to channelId.
+// Subscriptions that are private are handled according to privatePolicy.
+func (s *Service) SubscriberCheck(channelId string, privatePolicy string) EligibilityCheck {
+	return func(candidate *Candidate) (string, error) {
+		if candidate.AuthorChannelId == "" {
+			return noChannelReason, nil
+		}
+		resp, err := s.ytService.Subscriptions.List([]string{"id"}).
+			ChannelId(candidate.AuthorChannelId).
+			ForChannelId(channelId).
+			Do()
+		if isSubscriptionForbidden(err) {
+			if privatePolicy == AllowPrivate {
+				return "", nil
+			}
+			return privateReason, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("could not check subscription of %s: %w", candidate.AuthorDisplayName, err)
+		}
+		if len(resp.Items) == 0 {
+			return notSubscribedReason, nil
+		}
+		return "", nil
+	}
+}
+
+// redrawReasonErr checks that the rules could have produced a redraw for
+// reason. Redraws are taken on the word of whoever ran the draw, but only for
+// reasons the subscriber check gives and only when it was turned on.
+func (r Rules) redrawReasonErr(reason string) error {
+	if !r.SubscribersOnly {
+		return fmt.Errorf("%w: %q without the subscriber check", RedrawErr, reason)
+	}
+	switch reason {
+	case noChannelReason, notSubscribedReason:
+		return nil
+	case privateReason:
+		if r.PrivateSubscriptions != AllowPrivate {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", RedrawErr, reason)
+}
+
+func isSubscriptionForbidden(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, item := range apiErr.Errors {
+		if item.Reason == "subscriptionForbidden" {
+			return true
+		}
+	}
+	return false
+}
+
+// videoChannelId returns the ID of the channel that uploaded the video.
+func (s *Service) videoChannelId(videoId string) (string, error) {
+	resp, err := s.ytService.Videos.List([]string{"snippet"}).Id(videoId).Do()
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Items) == 0 {
+		return "", fmt.Errorf("video %s not found", videoId)
+	}
+	return resp.Items[0].Snippet.ChannelId, nil
+}
@@ -0,0 +1,180 @@
+package youtube
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/api/youtube/v3"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entrant is a single giveaway entry taken from a top-level comment.
+//
+// Entrants can also be read from an export with ReadEntrants. Exports use the
+// JSON field names below, as a JSON array, as newline delimited JSON or as a
+// CSV file with those names in its header row. Every entrant needs a
+// commentId, authorChannelId and publishedAt, and CSV files also need the
+// authorDisplayName and text columns. Timestamps are RFC 3339 and updatedAt
+// defaults to publishedAt.
+type Entrant struct {
+	CommentId         string    `json:"commentId"`
+	VideoId           string    `json:"videoId"`
+	AuthorChannelId   string    `json:"authorChannelId"`
+	AuthorDisplayName string    `json:"authorDisplayName"`
+	Text              string    `json:"text"`
+	LikeCount         int64     `json:"likeCount"`
+	ReplyCount        int64     `json:"replyCount"`
+	PublishedAt       time.Time `json:"publishedAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+func newEntrant(thread *youtube.CommentThread) *Entrant {
+	comment := thread.Snippet.TopLevelComment
+	snippet := comment.Snippet
+
+	entrant := &Entrant{
+		CommentId:         comment.Id,
+		VideoId:           thread.Snippet.VideoId,
+		AuthorDisplayName: snippet.AuthorDisplayName,
+		Text:              snippet.TextOriginal,
+		LikeCount:         snippet.LikeCount,
+		ReplyCount:        thread.Snippet.TotalReplyCount,
+	}
+	if snippet.AuthorChannelId != nil {
+		entrant.AuthorChannelId = snippet.AuthorChannelId.Value
+	}
+	// the API always sends RFC 3339 timestamps, a zero time means it was missing
+	entrant.PublishedAt, _ = time.Parse(time.RFC3339, snippet.PublishedAt)
+	entrant.UpdatedAt, _ = time.Parse(time.RFC3339, snippet.UpdatedAt)
+	return entrant
+}
+
+var requiredEntrantFields = []string{"commentId", "authorChannelId", "authorDisplayName", "text", "publishedAt"}
+
+// ReadEntrants reads an entrant export. The format is picked from the file
+// extension: .csv, .ndjson or .jsonl, and JSON otherwise.
+func ReadEntrants(path string) ([]*Entrant, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entrants []*Entrant
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		entrants, err = readEntrantsCsv(data)
+	case ".ndjson", ".jsonl":
+		entrants, err = readEntrantsNdjson(data)
+	default:
+		err = json.Unmarshal(data, &entrants)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i, entrant := range entrants {
+		if entrant.CommentId == "" || entrant.AuthorChannelId == "" || entrant.PublishedAt.IsZero() {
+			return nil, fmt.Errorf("%s: entrant %d needs a commentId, authorChannelId and publishedAt", path, i+1)
+		}
+		if entrant.UpdatedAt.IsZero() {
+			entrant.UpdatedAt = entrant.PublishedAt
+		}
+	}
+	return entrants, nil
+}
+
+func readEntrantsNdjson(data []byte) ([]*Entrant, error) {
+	entrants := make([]*Entrant, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		entrant := new(Entrant)
+		if err := json.Unmarshal(scanner.Bytes(), entrant); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entrants = append(entrants, entrant)
+	}
+	return entrants, scanner.Err()
+}
+
+func readEntrantsCsv(data []byte) ([]*Entrant, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range requiredEntrantFields {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	entrants := make([]*Entrant, 0)
+	for line := 2; ; line++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			return entrants, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return row[i]
+			}
+			return ""
+		}
+
+		entrant := &Entrant{
+			CommentId:         field("commentId"),
+			VideoId:           field("videoId"),
+			AuthorChannelId:   field("authorChannelId"),
+			AuthorDisplayName: field("authorDisplayName"),
+			Text:              field("text"),
+		}
+		if entrant.PublishedAt, err = time.Parse(time.RFC3339, field("publishedAt")); err != nil {
+			return nil, fmt.Errorf("line %d: publishedAt: %w", line, err)
+		}
+		if updatedAt := field("updatedAt"); updatedAt != "" {
+			if entrant.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+				return nil, fmt.Errorf("line %d: updatedAt: %w", line, err)
+			}
+		}
+		for name, count := range map[string]*int64{"likeCount": &entrant.LikeCount, "replyCount": &entrant.ReplyCount} {
+			if value := field(name); value != "" {
+				if *count, err = strconv.ParseInt(value, 10, 64); err != nil {
+					return nil, fmt.Errorf("line %d: %s: %w", line, name, err)
+				}
+			}
+		}
+		entrants = append(entrants, entrant)
+	}
+}
+
+// EntrantVideos returns the distinct video IDs of the entrants in the order
+// they first appear.
+func EntrantVideos(entrants []*Entrant) []string {
+	seen := make(map[string]bool)
+	videos := make([]string, 0)
+	for _, entrant := range entrants {
+		if entrant.VideoId != "" && !seen[entrant.VideoId] {
+			seen[entrant.VideoId] = true
+			videos = append(videos, entrant.VideoId)
+		}
+	}
+	return videos
+}
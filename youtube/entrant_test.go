@@ -0,0 +1,100 @@
+package youtube
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadEntrants(t *testing.T) {
+	publishedAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	updatedAt := publishedAt.Add(time.Hour)
+	want := []*Entrant{
+		{
+			CommentId:         "c1",
+			VideoId:           "v1",
+			AuthorChannelId:   "UC1",
+			AuthorDisplayName: "one",
+			Text:              "count me in, #giveaway",
+			LikeCount:         3,
+			ReplyCount:        1,
+			PublishedAt:       publishedAt,
+			UpdatedAt:         updatedAt,
+		},
+		{
+			CommentId:         "c2",
+			AuthorChannelId:   "UC2",
+			AuthorDisplayName: "two",
+			Text:              "me too",
+			PublishedAt:       publishedAt,
+			UpdatedAt:         publishedAt,
+		},
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"entrants.csv", `commentId,videoId,authorChannelId,authorDisplayName,text,publishedAt,updatedAt,likeCount,replyCount
+c1,v1,UC1,one,"count me in, #giveaway",2021-08-01T12:00:00Z,2021-08-01T13:00:00Z,3,1
+c2,,UC2,two,me too,2021-08-01T12:00:00Z,,,
+`},
+		{"entrants.ndjson", `{"commentId":"c1","videoId":"v1","authorChannelId":"UC1","authorDisplayName":"one","text":"count me in, #giveaway","likeCount":3,"replyCount":1,"publishedAt":"2021-08-01T12:00:00Z","updatedAt":"2021-08-01T13:00:00Z"}
+
+{"commentId":"c2","authorChannelId":"UC2","authorDisplayName":"two","text":"me too","publishedAt":"2021-08-01T12:00:00Z"}
+`},
+		{"entrants.json", `[
+  {"commentId":"c1","videoId":"v1","authorChannelId":"UC1","authorDisplayName":"one","text":"count me in, #giveaway","likeCount":3,"replyCount":1,"publishedAt":"2021-08-01T12:00:00Z","updatedAt":"2021-08-01T13:00:00Z"},
+  {"commentId":"c2","authorChannelId":"UC2","authorDisplayName":"two","text":"me too","publishedAt":"2021-08-01T12:00:00Z"}
+]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.name)
+			if err := ioutil.WriteFile(path, []byte(test.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			entrants, err := ReadEntrants(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entrants) != len(want) {
+				t.Fatalf("read %d entrants, want %d", len(entrants), len(want))
+			}
+			for i, entrant := range entrants {
+				if *entrant != *want[i] {
+					t.Errorf("entrant %d = %+v, want %+v", i, *entrant, *want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadEntrantsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"missing.csv", "commentId,authorChannelId,authorDisplayName,publishedAt\nc1,UC1,one,2021-08-01T12:00:00Z\n", `missing column "text"`},
+		{"time.csv", "commentId,authorChannelId,authorDisplayName,text,publishedAt\nc1,UC1,one,hi,yesterday\n", "line 2: publishedAt"},
+		{"count.csv", "commentId,authorChannelId,authorDisplayName,text,publishedAt,likeCount\nc1,UC1,one,hi,2021-08-01T12:00:00Z,many\n", "line 2: likeCount"},
+		{"bad.ndjson", `{"commentId":"c1"}` + "\n{\n", "line 2"},
+		{"channel.json", `[{"commentId":"c1","publishedAt":"2021-08-01T12:00:00Z"}]`, "entrant 1 needs"},
+		{"published.jsonl", `{"commentId":"c1","authorChannelId":"UC1"}`, "entrant 1 needs"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.name)
+			if err := ioutil.WriteFile(path, []byte(test.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ReadEntrants(path)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ReadEntrants() = %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
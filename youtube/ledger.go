@@ -0,0 +1,136 @@
+package youtube
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var ledgerFileName = "winners.jsonl"
+
+// LedgerEntry is one line of the winners ledger. A winner who was rerolled
+// out of a draw gets a second entry with Disqualified set and the reason.
+type LedgerEntry struct {
+	DrawId            string    `json:"drawId"`
+	AuthorChannelId   string    `json:"authorChannelId"`
+	AuthorDisplayName string    `json:"authorDisplayName"`
+	Videos            []string  `json:"videos"`
+	WonAt             time.Time `json:"wonAt"`
+	Disqualified      bool      `json:"disqualified,omitempty"`
+	Reason            string    `json:"reason,omitempty"`
+}
+
+// LedgerPath is the winners ledger every draw is appended to.
+func LedgerPath() string {
+	return filepath.Join(home, dataDirName, ledgerFileName)
+}
+
+// AppendLedger adds winners of the record to the ledger.
+func AppendLedger(record *DrawRecord, winners []*Winner) error {
+	wonAt := time.Now().UTC()
+	entries := make([]*LedgerEntry, 0, len(winners))
+	for _, winner := range winners {
+		entries = append(entries, newLedgerEntry(record, winner, wonAt))
+	}
+	return appendLedger(entries)
+}
+
+// AppendLedgerRerolls adds the outcome of rerolls to the ledger: the
+// disqualified winners are marked as such and the promoted alternates won.
+func AppendLedgerRerolls(record *DrawRecord, rerolls []*Reroll) error {
+	entries := make([]*LedgerEntry, 0, 2*len(rerolls))
+	for _, reroll := range rerolls {
+		disqualified := newLedgerEntry(record, reroll.Disqualified, reroll.RerolledAt)
+		disqualified.Disqualified = true
+		disqualified.Reason = reroll.Reason
+		entries = append(entries, disqualified, newLedgerEntry(record, reroll.Promoted, reroll.RerolledAt))
+	}
+	return appendLedger(entries)
+}
+
+func newLedgerEntry(record *DrawRecord, winner *Winner, wonAt time.Time) *LedgerEntry {
+	return &LedgerEntry{
+		DrawId:            record.Id,
+		AuthorChannelId:   winner.AuthorChannelId,
+		AuthorDisplayName: winner.AuthorDisplayName,
+		Videos:            record.Videos,
+		WonAt:             wonAt,
+	}
+}
+
+func appendLedger(entries []*LedgerEntry) error {
+	path := LedgerPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadLedger returns every entry in the ledger. A missing ledger is empty.
+func ReadLedger() ([]*LedgerEntry, error) {
+	entries := make([]*LedgerEntry, 0)
+	file, err := os.Open(LedgerPath())
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		entry := new(LedgerEntry)
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", LedgerPath(), line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// RecentWinners returns the channel IDs that won at or after since, leaving
+// out winners who were disqualified from the draw they won.
+func RecentWinners(since time.Time) ([]string, error) {
+	entries, err := ReadLedger()
+	if err != nil {
+		return nil, err
+	}
+	disqualified := make(map[[2]string]bool)
+	for _, entry := range entries {
+		if entry.Disqualified {
+			disqualified[[2]string{entry.DrawId, entry.AuthorChannelId}] = true
+		}
+	}
+	seen := make(map[string]bool)
+	winners := make([]string, 0)
+	for _, entry := range entries {
+		if entry.WonAt.Before(since) || entry.AuthorChannelId == "" || seen[entry.AuthorChannelId] {
+			continue
+		}
+		if entry.Disqualified || disqualified[[2]string{entry.DrawId, entry.AuthorChannelId}] {
+			continue
+		}
+		seen[entry.AuthorChannelId] = true
+		winners = append(winners, entry.AuthorChannelId)
+	}
+	return winners, nil
+}
@@ -0,0 +1,40 @@
+package youtube
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRecentWinners(t *testing.T) {
+	home = t.TempDir()
+	record := testRecord(t)
+	if err := AppendLedger(record, record.Winners); err != nil {
+		t.Fatal(err)
+	}
+	disqualified := record.Winners[0].AuthorChannelId
+	reroll, err := record.Reroll(disqualified, "did not claim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendLedgerRerolls(record, []*Reroll{reroll}); err != nil {
+		t.Fatal(err)
+	}
+
+	winners, err := RecentWinners(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{record.Winners[1].AuthorChannelId, reroll.Promoted.AuthorChannelId}
+	if fmt.Sprint(winners) != fmt.Sprint(want) {
+		t.Errorf("RecentWinners() = %v, want %v", winners, want)
+	}
+
+	winners, err = RecentWinners(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(winners) != 0 {
+		t.Errorf("RecentWinners() in the future = %v, want none", winners)
+	}
+}
@@ -0,0 +1,31 @@
+package youtube
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// ReadChannelList reads channel IDs from a text file, one per line. Blank
+// lines and everything after a # are ignored.
+func ReadChannelList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	channels := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line != "" {
+			channels = append(channels, line)
+		}
+	}
+	return channels, scanner.Err()
+}
@@ -0,0 +1,145 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/api/youtube/v3"
+	"time"
+)
+
+var (
+	NotLiveErr      = errors.New("video does not have an active live chat")
+	LateLiveDrawErr = errors.New("live draws have to start before the entry window opens")
+)
+
+// PrepareLiveDraw collects the chat messages of an active broadcast until the
+// entry window closes and returns a draw record for them that has not been
+// run yet. Every text message becomes an entrant, so the entry rules and the
+// unique entrant pool apply exactly as they do to comments. The API only
+// returns chat sent while it is being read, so the window must not have
+// opened yet.
+func (s *Service) PrepareLiveDraw(broadcastUrl string, rules Rules, seed string) (*DrawRecord, error) {
+	if rules.Entry.Closes == nil {
+		return nil, errors.New("live draws need a time the entry window closes")
+	}
+	if rules.Entry.Opens != nil && time.Now().After(*rules.Entry.Opens) {
+		return nil, fmt.Errorf("%w: it opened at %s and earlier chat cannot be read back", LateLiveDrawErr, rules.Entry.Opens.Local().Format("2006-01-02 15:04:05"))
+	}
+	videoId, err := parseVideoUrl(broadcastUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.ytService.Videos.List([]string{"snippet", "liveStreamingDetails"}).Id(videoId).Do()
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Items) == 0 {
+		return nil, fmt.Errorf("video %s not found", videoId)
+	}
+	video := resp.Items[0]
+	if video.LiveStreamingDetails == nil || video.LiveStreamingDetails.ActiveLiveChatId == "" {
+		return nil, NotLiveErr
+	}
+
+	entrants, err := s.pollLiveChat(videoId, video.LiveStreamingDetails.ActiveLiveChatId, *rules.Entry.Closes)
+	if err != nil {
+		return nil, err
+	}
+
+	record := NewDrawRecord([]string{videoId}, entrants, rules, seed)
+	record.ChannelId = video.Snippet.ChannelId
+	record.LiveChat = true
+	return record, s.ScreenChannels(record)
+}
+
+// pollLiveChat reads the live chat page by page, waiting as long as the API
+// asks between polls, until a page arrives after closes or the chat ends.
+func (s *Service) pollLiveChat(videoId string, liveChatId string, closes time.Time) ([]*Entrant, error) {
+	chat := newLiveChatEntrants(videoId)
+	start := time.Now()
+
+	chatRequest := s.ytService.LiveChatMessages.List(liveChatId, []string{"snippet", "authorDetails"})
+	pageToken := ""
+	for {
+		resp, err := chatRequest.PageToken(pageToken).Do()
+		if err != nil {
+			return nil, err
+		}
+		chat.add(resp.Items)
+		fmt.Printf("\rcollecting live chat entries... (%d messages, %ds)", len(chat.byId), int(time.Since(start).Seconds()))
+
+		if resp.OfflineAt != "" || time.Now().After(closes) {
+			break
+		}
+		pageToken = resp.NextPageToken
+		time.Sleep(time.Duration(resp.PollingIntervalMillis) * time.Millisecond)
+	}
+	fmt.Println()
+	return chat.collected(), nil
+}
+
+// liveChatEntrants turns the pages of a live chat into entrants as they are
+// read.
+type liveChatEntrants struct {
+	videoId  string
+	entrants []*Entrant
+	// byId is the index of every text message in entrants
+	byId map[string]int
+}
+
+func newLiveChatEntrants(videoId string) *liveChatEntrants {
+	return &liveChatEntrants{
+		videoId:  videoId,
+		entrants: make([]*Entrant, 0),
+		byId:     make(map[string]int),
+	}
+}
+
+// add turns the text messages of a page into entrants, and takes the entrants
+// of messages deleted since back out.
+func (c *liveChatEntrants) add(messages []*youtube.LiveChatMessage) {
+	for _, message := range messages {
+		switch message.Snippet.Type {
+		case "textMessageEvent":
+			c.byId[message.Id] = len(c.entrants)
+			c.entrants = append(c.entrants, newLiveEntrant(c.videoId, message))
+		case "messageDeletedEvent":
+			// deleted messages are not entries, drop them from the snapshot
+			if i, ok := c.byId[message.Snippet.MessageDeletedDetails.DeletedMessageId]; ok {
+				c.entrants[i] = nil
+			}
+		}
+	}
+}
+
+// collected returns the entrants of every message that was not deleted, in
+// the order they were sent.
+func (c *liveChatEntrants) collected() []*Entrant {
+	collected := make([]*Entrant, 0, len(c.entrants))
+	for _, entrant := range c.entrants {
+		if entrant != nil {
+			collected = append(collected, entrant)
+		}
+	}
+	return collected
+}
+
+func newLiveEntrant(videoId string, message *youtube.LiveChatMessage) *Entrant {
+	entrant := &Entrant{
+		CommentId:       message.Id,
+		VideoId:         videoId,
+		AuthorChannelId: message.Snippet.AuthorChannelId,
+		Text:            message.Snippet.DisplayMessage,
+	}
+	if message.Snippet.TextMessageDetails != nil {
+		entrant.Text = message.Snippet.TextMessageDetails.MessageText
+	}
+	if message.AuthorDetails != nil {
+		entrant.AuthorDisplayName = message.AuthorDetails.DisplayName
+	}
+	// chat messages cannot be edited, so they were last updated when sent
+	entrant.PublishedAt, _ = time.Parse(time.RFC3339, message.Snippet.PublishedAt)
+	entrant.UpdatedAt = entrant.PublishedAt
+	return entrant
+}
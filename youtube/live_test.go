@@ -0,0 +1,84 @@
+package youtube
+
+import (
+	"fmt"
+	"google.golang.org/api/youtube/v3"
+	"testing"
+	"time"
+)
+
+func chatMessage(id string, channel string, text string) *youtube.LiveChatMessage {
+	return &youtube.LiveChatMessage{
+		Id: id,
+		Snippet: &youtube.LiveChatMessageSnippet{
+			Type:               "textMessageEvent",
+			AuthorChannelId:    channel,
+			DisplayMessage:     text,
+			PublishedAt:        "2021-08-01T20:05:00Z",
+			TextMessageDetails: &youtube.LiveChatTextMessageDetails{MessageText: text},
+		},
+		AuthorDetails: &youtube.LiveChatMessageAuthorDetails{DisplayName: "name of " + channel},
+	}
+}
+
+func deletedMessage(id string) *youtube.LiveChatMessage {
+	return &youtube.LiveChatMessage{
+		Id: "deleted-" + id,
+		Snippet: &youtube.LiveChatMessageSnippet{
+			Type:                  "messageDeletedEvent",
+			MessageDeletedDetails: &youtube.LiveChatMessageDeletedDetails{DeletedMessageId: id},
+		},
+	}
+}
+
+func TestNewLiveEntrant(t *testing.T) {
+	entrant := newLiveEntrant("video", chatMessage("m1", "UC1", "!enter"))
+	publishedAt := time.Date(2021, 8, 1, 20, 5, 0, 0, time.UTC)
+	want := Entrant{
+		CommentId:         "m1",
+		VideoId:           "video",
+		AuthorChannelId:   "UC1",
+		AuthorDisplayName: "name of UC1",
+		Text:              "!enter",
+		PublishedAt:       publishedAt,
+		UpdatedAt:         publishedAt,
+	}
+	if fmt.Sprint(*entrant) != fmt.Sprint(want) {
+		t.Errorf("newLiveEntrant() = %+v, want %+v", *entrant, want)
+	}
+
+	// without text details the displayed message is the text
+	message := chatMessage("m2", "UC2", "hi")
+	message.Snippet.TextMessageDetails = nil
+	message.Snippet.DisplayMessage = "shown"
+	message.AuthorDetails = nil
+	entrant = newLiveEntrant("video", message)
+	if entrant.Text != "shown" || entrant.AuthorDisplayName != "" {
+		t.Errorf("newLiveEntrant() without details = %+v", *entrant)
+	}
+}
+
+func TestLiveChatEntrants(t *testing.T) {
+	chat := newLiveChatEntrants("video")
+	chat.add([]*youtube.LiveChatMessage{
+		chatMessage("m1", "UC1", "!enter"),
+		chatMessage("m2", "UC2", "!enter"),
+		{Id: "s1", Snippet: &youtube.LiveChatMessageSnippet{Type: "superChatEvent"}},
+	})
+	chat.add([]*youtube.LiveChatMessage{
+		deletedMessage("m1"),
+		chatMessage("m3", "UC3", "!enter"),
+		deletedMessage("unknown"),
+	})
+	chat.add([]*youtube.LiveChatMessage{
+		chatMessage("m4", "UC1", "!enter again"),
+	})
+
+	ids := make([]string, 0)
+	for _, entrant := range chat.collected() {
+		ids = append(ids, entrant.CommentId)
+	}
+	if fmt.Sprint(ids) != "[m2 m3 m4]" {
+		t.Errorf("collected() = %v, want [m2 m3 m4]", ids)
+	}
+}
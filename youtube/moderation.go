@@ -0,0 +1,48 @@
+package youtube
+
+import (
+	"fmt"
+)
+
+const (
+	// HeldForReview is the moderation status of comments waiting for approval.
+	HeldForReview = "heldForReview"
+	// LikelySpam is the moderation status of comments youtube thinks are spam.
+	LikelySpam = "likelySpam"
+
+	// Approve publishes comments.
+	Approve = "approve"
+	// Reject hides comments.
+	Reject = "reject"
+	// Ban hides comments and every future comment of their authors.
+	Ban = "ban"
+)
+
+// Moderate approves, rejects or bans the comments with the given IDs. Only
+// the channel owner can moderate comments.
+func (s *Service) Moderate(commentIds []string, action string) error {
+	status := ""
+	switch action {
+	case Approve:
+		status = "published"
+	case Reject, Ban:
+		status = "rejected"
+	default:
+		return fmt.Errorf("moderation action must be %q, %q or %q", Approve, Reject, Ban)
+	}
+
+	for start := 0; start < len(commentIds); start += commentBatchSize {
+		end := start + commentBatchSize
+		if end > len(commentIds) {
+			end = len(commentIds)
+		}
+		moderateRequest := s.ytService.Comments.SetModerationStatus(commentIds[start:end], status)
+		if action == Ban {
+			moderateRequest = moderateRequest.BanAuthor(true)
+		}
+		if err := moderateRequest.Do(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
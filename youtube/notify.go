@@ -0,0 +1,44 @@
+package youtube
+
+import (
+	"google.golang.org/api/youtube/v3"
+	"strings"
+)
+
+// DefaultNotification is the reply posted to winners when no template is set.
+var DefaultNotification = "Congratulations {winner}, you won the giveaway! {claim}"
+
+// Notification is a templated reply to a winning comment. In Template,
+// {winner} is replaced by the winner's display name, {prize} by their prize
+// and {claim} by Claim. Display names are usually handles that start with @
+// already, so @{winner} does not add a second one.
+type Notification struct {
+	Template string `json:"template"`
+	Claim    string `json:"claim"`
+}
+
+// Text fills in the template for winner.
+func (n Notification) Text(winner *Winner) string {
+	template := n.Template
+	if template == "" {
+		template = DefaultNotification
+	}
+	replacer := strings.NewReplacer(
+		"@{winner}", "@"+strings.TrimPrefix(winner.AuthorDisplayName, "@"),
+		"{winner}", winner.AuthorDisplayName,
+		"{prize}", winner.Prize,
+		"{claim}", n.Claim,
+	)
+	return strings.TrimSpace(replacer.Replace(template))
+}
+
+// ReplyToComment posts text as a reply to the top-level comment parentId.
+func (s *Service) ReplyToComment(parentId string, text string) (*youtube.Comment, error) {
+	comment := &youtube.Comment{
+		Snippet: &youtube.CommentSnippet{
+			ParentId:     parentId,
+			TextOriginal: text,
+		},
+	}
+	return s.ytService.Comments.Insert([]string{"snippet"}, comment).Do()
+}
@@ -0,0 +1,26 @@
+package youtube
+
+import "testing"
+
+func TestNotificationText(t *testing.T) {
+	tests := []struct {
+		template string
+		name     string
+		want     string
+	}{
+		{"", "@handle", "Congratulations @handle, you won the giveaway! claim by DM"},
+		{"Congratulations @{winner}!", "@handle", "Congratulations @handle!"},
+		{"Congratulations @{winner}!", "Old Name", "Congratulations @Old Name!"},
+		{"{winner} won {prize}. {claim}", "@handle", "@handle won Grand prize. claim by DM"},
+		{"{winner} won {prize}.", "@handle", "@handle won Grand prize."},
+	}
+	for _, test := range tests {
+		t.Run(test.template, func(t *testing.T) {
+			notification := Notification{Template: test.template, Claim: "claim by DM"}
+			winner := &Winner{AuthorDisplayName: test.name, Prize: "Grand prize"}
+			if got := notification.Text(winner); got != test.want {
+				t.Errorf("Text() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
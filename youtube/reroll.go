@@ -0,0 +1,121 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	NoAlternatesErr = errors.New("no alternates left to promote")
+	NotAWinnerErr   = errors.New("not a current winner")
+)
+
+// Reroll records a winner who was disqualified after the draw and the
+// alternate who took their place.
+type Reroll struct {
+	Disqualified *Winner   `json:"disqualified"`
+	Promoted     *Winner   `json:"promoted"`
+	Reason       string    `json:"reason"`
+	RerolledAt   time.Time `json:"rerolledAt"`
+}
+
+// Reroll disqualifies the winner matching who, a channel ID, comment ID or
+// display name, and promotes the next alternate into their place. It works entirely
+// from the record.
+func (r *DrawRecord) Reroll(who string, reason string) (*Reroll, error) {
+	winner, err := r.findWinner(who)
+	if err != nil {
+		return nil, err
+	}
+	return r.reroll(winner, reason)
+}
+
+// RerollWinners rerolls every winner in who, or none of them: nothing changes
+// when a name matches no winner, two names match the same winner or there are
+// fewer alternates left than winners to replace.
+func (r *DrawRecord) RerollWinners(who []string, reason string) ([]*Reroll, error) {
+	winners := make([]*Winner, 0, len(who))
+	names := make(map[*Winner]string)
+	for _, name := range who {
+		winner, err := r.findWinner(name)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := names[winner]; ok {
+			return nil, fmt.Errorf("%q and %q are the same winner", other, name)
+		}
+		names[winner] = name
+		winners = append(winners, winner)
+	}
+	if len(winners) > len(r.Alternates) {
+		return nil, fmt.Errorf("%w: %d winners to replace, %d alternates left", NoAlternatesErr, len(winners), len(r.Alternates))
+	}
+
+	rerolls := make([]*Reroll, 0, len(winners))
+	for _, winner := range winners {
+		reroll, err := r.reroll(winner, reason)
+		if err != nil {
+			return nil, err
+		}
+		rerolls = append(rerolls, reroll)
+	}
+	return rerolls, nil
+}
+
+func (r *DrawRecord) reroll(winner *Winner, reason string) (*Reroll, error) {
+	promoted, err := r.promote(winner.CommentId)
+	if err != nil {
+		return nil, err
+	}
+
+	reroll := &Reroll{
+		Disqualified: winner,
+		Promoted:     promoted,
+		Reason:       reason,
+		RerolledAt:   time.Now().UTC(),
+	}
+	r.Rerolls = append(r.Rerolls, reroll)
+	return reroll, nil
+}
+
+func (r *DrawRecord) findWinner(who string) (*Winner, error) {
+	for _, winner := range r.Winners {
+		if who != "" && (winner.AuthorChannelId == who || winner.CommentId == who) {
+			return winner, nil
+		}
+	}
+
+	var found *Winner
+	for _, winner := range r.Winners {
+		if strings.EqualFold(winner.AuthorDisplayName, who) {
+			if found != nil {
+				return nil, fmt.Errorf("%q matches several winners, use their channel ID", who)
+			}
+			found = winner
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%q: %w", who, NotAWinnerErr)
+	}
+	return found, nil
+}
+
+// promote replaces the winner of commentId by the first alternate, who takes
+// over their prize.
+func (r *DrawRecord) promote(commentId string) (*Winner, error) {
+	if len(r.Alternates) == 0 {
+		return nil, NoAlternatesErr
+	}
+	for i, winner := range r.Winners {
+		if winner.CommentId == commentId {
+			promoted := *r.Alternates[0]
+			promoted.Prize = winner.Prize
+			r.Winners[i] = &promoted
+			r.Alternates = r.Alternates[1:]
+			return &promoted, nil
+		}
+	}
+	return nil, NotAWinnerErr
+}
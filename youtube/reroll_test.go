@@ -0,0 +1,60 @@
+package youtube
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRerollWinners(t *testing.T) {
+	tests := []struct {
+		name     string
+		who      []string
+		promoted []string
+		wantErr  bool
+	}{
+		{"by channel", []string{"UC3"}, []string{"user0"}, false},
+		{"by name", []string{"USER2"}, []string{"user0"}, false},
+		{"by comment", []string{"comment2"}, []string{"user0"}, false},
+		{"several", []string{"user3", "UC2"}, []string{"user0", "user5"}, false},
+		{"not a winner", []string{"UC3", "user9"}, nil, true},
+		{"same winner twice", []string{"UC3", "user3"}, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			record := testRecord(t)
+			before := winnerNames(record.Winners)
+			rerolls, err := record.RerollWinners(test.who, "test")
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("RerollWinners() rerolled %d winners, want an error", len(rerolls))
+				}
+				if got := winnerNames(record.Winners); len(record.Rerolls) > 0 || len(record.Alternates) != 2 || got[0] != before[0] || got[1] != before[1] {
+					t.Errorf("failed reroll changed the record: winners %v, %d alternates", got, len(record.Alternates))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, reroll := range rerolls {
+				if reroll.Promoted.AuthorDisplayName != test.promoted[i] {
+					t.Errorf("promoted %q, want %q", reroll.Promoted.AuthorDisplayName, test.promoted[i])
+				}
+			}
+			if err := record.Verify(Published{}); err != nil {
+				t.Errorf("Verify() after reroll = %v", err)
+			}
+		})
+	}
+}
+
+func TestRerollWinnersNoAlternates(t *testing.T) {
+	record := testRecord(t)
+	record.Alternates = record.Alternates[:1]
+	if _, err := record.RerollWinners([]string{"UC3", "UC2"}, "test"); !errors.Is(err, NoAlternatesErr) {
+		t.Errorf("RerollWinners() = %v, want %v", err, NoAlternatesErr)
+	}
+	if len(record.Rerolls) > 0 {
+		t.Error("failed reroll changed the record")
+	}
+}
@@ -0,0 +1,201 @@
+package youtube
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// RejectLateEdits excludes comments edited after the entry window closed.
+	RejectLateEdits = "reject"
+	// FlagLateEdits keeps comments edited after the window closed but reports them.
+	FlagLateEdits = "flag"
+)
+
+var (
+	hashtagPattern = regexp.MustCompile(`#[\p{L}\p{N}_]+`)
+	// mentionPattern matches the characters of a handle, a sentence ending
+	// after one leaves a dot that is trimmed off
+	mentionPattern = regexp.MustCompile(`@[\p{L}\p{N}._-]+`)
+)
+
+// EntryRules are conditions every comment has to meet to enter the draw. They
+// are checked against the original comment text.
+type EntryRules struct {
+	// Contains lists substrings that must all appear, ignoring case.
+	Contains []string `json:"contains,omitempty"`
+	// Matches lists regular expressions that must all match.
+	Matches []string `json:"matches,omitempty"`
+	// Hashtags lists hashtags that must all be used, ignoring case.
+	Hashtags []string `json:"hashtags,omitempty"`
+	// MinMentions is the number of distinct @mentions required.
+	MinMentions int `json:"minMentions,omitempty"`
+	// MinLength is the minimum text length in characters.
+	MinLength int `json:"minLength,omitempty"`
+	// Opens and Closes bound the entry window on the comment publish time.
+	Opens  *time.Time `json:"opens,omitempty"`
+	Closes *time.Time `json:"closes,omitempty"`
+	// LateEdits is RejectLateEdits or FlagLateEdits and decides what happens to
+	// comments edited after Closes. Rejecting is the default.
+	LateEdits string `json:"lateEdits,omitempty"`
+}
+
+// RuleReport is the number of comments a single rule excluded and how many
+// distinct people wrote them. Rules that only flag comments list the flagged
+// comment IDs instead.
+type RuleReport struct {
+	Rule     string   `json:"rule"`
+	Excluded int      `json:"excluded"`
+	People   int      `json:"people"`
+	Flagged  []string `json:"flagged,omitempty"`
+}
+
+type entryFilter struct {
+	name     string
+	flagOnly bool
+	keep     func(*Entrant) bool
+}
+
+// Validate reports rules that can never be applied, like a bad regex.
+func (e EntryRules) Validate() error {
+	if e.Opens != nil && e.Closes != nil && e.Closes.Before(*e.Opens) {
+		return fmt.Errorf("entry window closes before it opens")
+	}
+	if e.LateEdits != "" && e.LateEdits != RejectLateEdits && e.LateEdits != FlagLateEdits {
+		return fmt.Errorf("late edit policy must be %q or %q", RejectLateEdits, FlagLateEdits)
+	}
+	_, err := e.filters()
+	return err
+}
+
+func (e EntryRules) filters() ([]entryFilter, error) {
+	filters := make([]entryFilter, 0)
+	if e.Opens != nil {
+		opens := *e.Opens
+		filters = append(filters, entryFilter{
+			name: fmt.Sprintf("published before %s", opens.Format(time.RFC3339)),
+			keep: func(entrant *Entrant) bool {
+				return !entrant.PublishedAt.Before(opens)
+			},
+		})
+	}
+	if e.Closes != nil {
+		closes := *e.Closes
+		filters = append(filters, entryFilter{
+			name: fmt.Sprintf("published after %s", closes.Format(time.RFC3339)),
+			keep: func(entrant *Entrant) bool {
+				return !entrant.PublishedAt.After(closes)
+			},
+		})
+		filters = append(filters, entryFilter{
+			name:     fmt.Sprintf("edited after %s", closes.Format(time.RFC3339)),
+			flagOnly: e.LateEdits == FlagLateEdits,
+			keep: func(entrant *Entrant) bool {
+				return !entrant.UpdatedAt.After(closes)
+			},
+		})
+	}
+	for _, substr := range e.Contains {
+		lower := strings.ToLower(substr)
+		filters = append(filters, entryFilter{
+			name: fmt.Sprintf("contains %q", substr),
+			keep: func(entrant *Entrant) bool {
+				return strings.Contains(strings.ToLower(entrant.Text), lower)
+			},
+		})
+	}
+	for _, expr := range e.Matches {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("bad entry regex: %w", err)
+		}
+		filters = append(filters, entryFilter{
+			name: fmt.Sprintf("matches /%s/", expr),
+			keep: func(entrant *Entrant) bool {
+				return re.MatchString(entrant.Text)
+			},
+		})
+	}
+	for _, hashtag := range e.Hashtags {
+		tag := "#" + strings.ToLower(strings.TrimPrefix(hashtag, "#"))
+		filters = append(filters, entryFilter{
+			name: fmt.Sprintf("hashtag %s", tag),
+			keep: func(entrant *Entrant) bool {
+				for _, used := range hashtagPattern.FindAllString(entrant.Text, -1) {
+					if strings.ToLower(used) == tag {
+						return true
+					}
+				}
+				return false
+			},
+		})
+	}
+	if e.MinMentions > 0 {
+		filters = append(filters, entryFilter{
+			name: fmt.Sprintf("at least %d mentions", e.MinMentions),
+			keep: func(entrant *Entrant) bool {
+				mentions := make(map[string]bool)
+				for _, mention := range mentionPattern.FindAllString(entrant.Text, -1) {
+					if mention = strings.TrimRight(mention, "."); mention != "@" {
+						mentions[strings.ToLower(mention)] = true
+					}
+				}
+				return len(mentions) >= e.MinMentions
+			},
+		})
+	}
+	if e.MinLength > 0 {
+		filters = append(filters, entryFilter{
+			name: fmt.Sprintf("at least %d characters", e.MinLength),
+			keep: func(entrant *Entrant) bool {
+				return utf8.RuneCountInString(strings.TrimSpace(entrant.Text)) >= e.MinLength
+			},
+		})
+	}
+	return filters, nil
+}
+
+// applyFilters returns the entrants that pass every filter and how many
+// entrants each filter rejected. An entrant failing several filters is counted
+// once for each of them. Flag-only filters never reject an entrant.
+func applyFilters(entrants []*Entrant, filters []entryFilter) ([]*Entrant, []RuleReport) {
+	reports := make([]RuleReport, len(filters))
+	people := make([]map[string]bool, len(filters))
+	for i, filter := range filters {
+		reports[i].Rule = filter.name
+		people[i] = make(map[string]bool)
+	}
+
+	eligible := make([]*Entrant, 0, len(entrants))
+	for _, entrant := range entrants {
+		keep := true
+		flags := make([]int, 0)
+		for i, filter := range filters {
+			if filter.keep(entrant) {
+				continue
+			}
+			if filter.flagOnly {
+				flags = append(flags, i)
+				continue
+			}
+			reports[i].Excluded++
+			people[i][entrant.AuthorChannelId] = true
+			keep = false
+		}
+		if !keep {
+			continue
+		}
+		// only flag entrants that are still in the draw
+		for _, i := range flags {
+			reports[i].Flagged = append(reports[i].Flagged, entrant.CommentId)
+		}
+		eligible = append(eligible, entrant)
+	}
+	for i := range reports {
+		reports[i].People = len(people[i])
+	}
+	return eligible, reports
+}
@@ -0,0 +1,115 @@
+package youtube
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryRules(t *testing.T) {
+	opens := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	closes := opens.Add(time.Hour)
+	entrant := func(text string, publishedAt time.Time, updatedAt time.Time) *Entrant {
+		return &Entrant{AuthorChannelId: "UC1", Text: text, PublishedAt: publishedAt, UpdatedAt: updatedAt}
+	}
+	during := opens.Add(time.Minute)
+
+	tests := []struct {
+		name    string
+		rules   EntryRules
+		entrant *Entrant
+		keep    bool
+		flagged bool
+	}{
+		{"no rules", EntryRules{}, entrant("hi", during, during), true, false},
+		{"contains", EntryRules{Contains: []string{"Giveaway"}}, entrant("my GIVEAWAY entry", during, during), true, false},
+		{"does not contain", EntryRules{Contains: []string{"giveaway"}}, entrant("hi", during, during), false, false},
+		{"contains every", EntryRules{Contains: []string{"red", "blue"}}, entrant("red", during, during), false, false},
+		{"matches", EntryRules{Matches: []string{`^\d+$`}}, entrant("42", during, during), true, false},
+		{"does not match", EntryRules{Matches: []string{`^\d+$`}}, entrant("42!", during, during), false, false},
+		{"hashtag", EntryRules{Hashtags: []string{"Win"}}, entrant("#win this", during, during), true, false},
+		{"hashtag with #", EntryRules{Hashtags: []string{"#win"}}, entrant("#WIN", during, during), true, false},
+		{"hashtag prefix", EntryRules{Hashtags: []string{"win"}}, entrant("#winning", during, during), false, false},
+		{"mentions", EntryRules{MinMentions: 2}, entrant("@a and @b", during, during), true, false},
+		{"repeated mention", EntryRules{MinMentions: 2}, entrant("@a and @A", during, during), false, false},
+		{"mention punctuation", EntryRules{MinMentions: 2}, entrant("@bob, @bob! (@bob) @bob.", during, during), false, false},
+		{"mentions in a sentence", EntryRules{MinMentions: 2}, entrant("thanks @bob.smith, and @ann_b-1.", during, during), true, false},
+		{"bare @", EntryRules{MinMentions: 2}, entrant("@bob @. @ @", during, during), false, false},
+		{"length", EntryRules{MinLength: 3}, entrant(" héé ", during, during), true, false},
+		{"too short", EntryRules{MinLength: 3}, entrant(" hé ", during, during), false, false},
+		{"opens", EntryRules{Opens: &opens}, entrant("hi", opens, opens), true, false},
+		{"before opens", EntryRules{Opens: &opens}, entrant("hi", opens.Add(-time.Second), opens), false, false},
+		{"closes", EntryRules{Closes: &closes}, entrant("hi", closes, closes), true, false},
+		{"after closes", EntryRules{Closes: &closes}, entrant("hi", closes.Add(time.Second), closes.Add(time.Second)), false, false},
+		{"late edit", EntryRules{Closes: &closes}, entrant("hi", during, closes.Add(time.Second)), false, false},
+		{"flagged late edit", EntryRules{Closes: &closes, LateEdits: FlagLateEdits}, entrant("hi", during, closes.Add(time.Second)), true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filters, err := test.rules.filters()
+			if err != nil {
+				t.Fatal(err)
+			}
+			eligible, reports := applyFilters([]*Entrant{test.entrant}, filters)
+			if keep := len(eligible) == 1; keep != test.keep {
+				t.Errorf("kept = %v, want %v", keep, test.keep)
+			}
+			flagged := false
+			for _, report := range reports {
+				flagged = flagged || len(report.Flagged) > 0
+			}
+			if flagged != test.flagged {
+				t.Errorf("flagged = %v, want %v", flagged, test.flagged)
+			}
+		})
+	}
+}
+
+func TestApplyFiltersReport(t *testing.T) {
+	rules := EntryRules{Contains: []string{"giveaway"}, MinLength: 10}
+	filters, err := rules.filters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entrants := []*Entrant{
+		{AuthorChannelId: "UC1", Text: "giveaway entry"},
+		{AuthorChannelId: "UC2", Text: "hi"},
+		{AuthorChannelId: "UC2", Text: "hello"},
+		{AuthorChannelId: "UC3", Text: "giveaway"},
+	}
+	eligible, reports := applyFilters(entrants, filters)
+	if len(eligible) != 1 || eligible[0] != entrants[0] {
+		t.Errorf("eligible = %v, want the first entrant", eligible)
+	}
+	want := []RuleReport{
+		{Rule: `contains "giveaway"`, Excluded: 2, People: 1},
+		{Rule: "at least 10 characters", Excluded: 3, People: 2},
+	}
+	for i, report := range reports {
+		if report.Rule != want[i].Rule || report.Excluded != want[i].Excluded || report.People != want[i].People {
+			t.Errorf("report %d = %+v, want %+v", i, report, want[i])
+		}
+	}
+}
+
+func TestEntryRulesValidate(t *testing.T) {
+	opens := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	closes := opens.Add(-time.Hour)
+	tests := []struct {
+		name  string
+		rules EntryRules
+		ok    bool
+	}{
+		{"empty", EntryRules{}, true},
+		{"window", EntryRules{Opens: &closes, Closes: &opens}, true},
+		{"closes before opens", EntryRules{Opens: &opens, Closes: &closes}, false},
+		{"late edits", EntryRules{LateEdits: "keep"}, false},
+		{"bad regex", EntryRules{Matches: []string{"("}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.rules.Validate(); (err == nil) != test.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, test.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package youtube
+
+import (
+	"fmt"
+	"google.golang.org/api/youtube/v3"
+	"regexp"
+	"time"
+)
+
+var commentBatchSize = 50
+
+// CommentSelector picks the comments to act on. A comment is selected when it
+// matches every field that is set, and an empty selector selects nothing.
+type CommentSelector struct {
+	Ids     []string
+	Authors []string
+	// Match is a regular expression on the original comment text.
+	Match string
+	// After and Before bound the comment publish time.
+	After  *time.Time
+	Before *time.Time
+}
+
+// Empty reports whether no field of the selector is set.
+func (c CommentSelector) Empty() bool {
+	return len(c.Ids) == 0 && len(c.Authors) == 0 && c.Match == "" && c.After == nil && c.Before == nil
+}
+
+// Validate reports selectors that select nothing or cannot be applied.
+func (c CommentSelector) Validate() error {
+	_, err := c.regexp()
+	return err
+}
+
+func (c CommentSelector) regexp() (*regexp.Regexp, error) {
+	if c.Empty() {
+		return nil, fmt.Errorf("select comments by ID, author, text or time")
+	}
+	if c.Match == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(c.Match)
+	if err != nil {
+		return nil, fmt.Errorf("bad comment regex: %w", err)
+	}
+	return re, nil
+}
+
+// Select returns the comments of the threads, replies included, that the
+// selector matches.
+func (c CommentSelector) Select(threads []*CommentThread) ([]*youtube.Comment, error) {
+	re, err := c.regexp()
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]*youtube.Comment, 0)
+	for _, thread := range threads {
+		for _, comment := range append([]*youtube.Comment{thread.Comment}, thread.Replies...) {
+			if c.matches(comment, re) {
+				selected = append(selected, comment)
+			}
+		}
+	}
+	return selected, nil
+}
+
+func (c CommentSelector) matches(comment *youtube.Comment, re *regexp.Regexp) bool {
+	snippet := comment.Snippet
+	if len(c.Ids) > 0 && !containsId(c.Ids, comment.Id) {
+		return false
+	}
+	if len(c.Authors) > 0 && (snippet.AuthorChannelId == nil || !containsId(c.Authors, snippet.AuthorChannelId.Value)) {
+		return false
+	}
+	if re != nil && !re.MatchString(snippet.TextOriginal) {
+		return false
+	}
+	publishedAt, _ := time.Parse(time.RFC3339, snippet.PublishedAt)
+	if c.After != nil && publishedAt.Before(*c.After) {
+		return false
+	}
+	if c.Before != nil && publishedAt.After(*c.Before) {
+		return false
+	}
+	return true
+}
+
+func containsId(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// GetComments fetches comments, top-level or replies, by ID. IDs that do not
+// exist are left out.
+func (s *Service) GetComments(ids []string) ([]*youtube.Comment, error) {
+	comments := make([]*youtube.Comment, 0)
+	for start := 0; start < len(ids); start += commentBatchSize {
+		end := start + commentBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		resp, err := s.ytService.Comments.List([]string{"snippet"}).Id(ids[start:end]...).Do()
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, resp.Items...)
+	}
+	return comments, nil
+}
+
+// DeleteComment deletes a comment.
+func (s *Service) DeleteComment(id string) error {
+	return s.ytService.Comments.Delete(id).Do()
+}
+
+// MarkAsSpam flags comments as spam in batches and returns how many were
+// flagged. It stops at the first batch that fails.
+func (s *Service) MarkAsSpam(ids []string) (int, error) {
+	for start := 0; start < len(ids); start += commentBatchSize {
+		end := start + commentBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := s.ytService.Comments.MarkAsSpam(ids[start:end]).Do(); err != nil {
+			return start, err
+		}
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,87 @@
+package youtube
+
+import (
+	"google.golang.org/api/youtube/v3"
+	"testing"
+	"time"
+)
+
+func testComment(id string, author string, text string, publishedAt string) *youtube.Comment {
+	return &youtube.Comment{
+		Id: id,
+		Snippet: &youtube.CommentSnippet{
+			AuthorChannelId: &youtube.CommentSnippetAuthorChannelId{Value: author},
+			TextOriginal:    text,
+			PublishedAt:     publishedAt,
+		},
+	}
+}
+
+func TestCommentSelector(t *testing.T) {
+	threads := []*CommentThread{
+		{
+			Comment: testComment("c1", "UC1", "buy followers at example.com", "2021-08-01T12:00:00Z"),
+			Replies: []*youtube.Comment{
+				testComment("c1.r1", "UC2", "nice video", "2021-08-01T13:00:00Z"),
+			},
+		},
+		{Comment: testComment("c2", "UC2", "Buy now", "2021-08-02T12:00:00Z")},
+	}
+	after := time.Date(2021, 8, 1, 12, 30, 0, 0, time.UTC)
+	before := time.Date(2021, 8, 1, 13, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		selector CommentSelector
+		want     []string
+	}{
+		{"ids", CommentSelector{Ids: []string{"c2", "c1.r1"}}, []string{"c1.r1", "c2"}},
+		{"authors", CommentSelector{Authors: []string{"UC2"}}, []string{"c1.r1", "c2"}},
+		{"match", CommentSelector{Match: `(?i)^buy`}, []string{"c1", "c2"}},
+		{"case sensitive match", CommentSelector{Match: `^buy`}, []string{"c1"}},
+		{"after", CommentSelector{After: &after}, []string{"c1.r1", "c2"}},
+		{"before", CommentSelector{Before: &before}, []string{"c1", "c1.r1"}},
+		{"every field", CommentSelector{Authors: []string{"UC2"}, Match: "(?i)buy", After: &after}, []string{"c2"}},
+		{"nothing", CommentSelector{Ids: []string{"c3"}}, []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selected, err := test.selector.Select(threads)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]string, len(selected))
+			for i, comment := range selected {
+				ids[i] = comment.Id
+			}
+			if len(ids) != len(test.want) {
+				t.Fatalf("selected %v, want %v", ids, test.want)
+			}
+			for i := range ids {
+				if ids[i] != test.want[i] {
+					t.Fatalf("selected %v, want %v", ids, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommentSelectorValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector CommentSelector
+		ok       bool
+	}{
+		{"empty", CommentSelector{}, false},
+		{"bad regex", CommentSelector{Match: "("}, false},
+		{"regex", CommentSelector{Match: "spam"}, true},
+		{"ids", CommentSelector{Ids: []string{"c1"}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.selector.Validate(); (err == nil) != test.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, test.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package youtube
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"io/ioutil"
+	"strconv"
+)
+
+var snapshotHeader = []string{"channel_sha256", "display_name", "tickets"}
+
+// HashChannelId is the SHA-256 of salt followed by a channel ID, as hex.
+// Draws salt with their commitment, so the same channel hashes differently in
+// every draw and viewers can hash their own channel ID with the published
+// commitment to find themselves in a snapshot. Channel IDs are public, so the
+// hashes do not hide who entered from anyone who checks a given channel.
+func HashChannelId(salt string, channelId string) string {
+	sum := sha256.Sum256([]byte(salt + channelId))
+	return hex.EncodeToString(sum[:])
+}
+
+// EntrantSnapshot renders the draw pool as CSV with one row per candidate:
+// the hashed channel ID, the display name and the ticket count.
+func (r *DrawRecord) EntrantSnapshot(pool []*Candidate) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	if err := w.Write(snapshotHeader); err != nil {
+		return nil, err
+	}
+	for _, candidate := range pool {
+		row := []string{
+			HashChannelId(r.Commitment, candidate.key),
+			candidate.AuthorDisplayName,
+			strconv.FormatInt(candidate.Tickets, 10),
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// EntrantSnapshotDigest returns the SHA-256 of the snapshot of the pool as
+// hex, without writing the snapshot anywhere.
+func (r *DrawRecord) EntrantSnapshotDigest(pool []*Candidate) (string, error) {
+	data, err := r.EntrantSnapshot(pool)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// WriteEntrantSnapshot writes the snapshot of the pool to path and returns
+// the SHA-256 of the file as hex.
+func (r *DrawRecord) WriteEntrantSnapshot(path string, pool []*Candidate) (string, error) {
+	data, err := r.EntrantSnapshot(pool)
+	if err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
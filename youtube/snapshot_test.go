@@ -0,0 +1,37 @@
+package youtube
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEntrantSnapshot(t *testing.T) {
+	tests := []struct {
+		name string
+		seed string
+		salt string
+	}{
+		{"salted", "seed", Commitment("seed")},
+		{"other seed", "other", Commitment("other")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			record := NewDrawRecord([]string{"video"}, testEntrants(), Rules{Count: 1}, test.seed)
+			pool, _, err := record.Pool()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := record.EntrantSnapshot(pool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if len(lines) != len(pool)+1 || lines[0] != "channel_sha256,display_name,tickets" {
+				t.Fatalf("snapshot =\n%s", data)
+			}
+			if want := HashChannelId(test.salt, "UC0") + ",user0,1"; lines[1] != want {
+				t.Errorf("first row = %q, want %q", lines[1], want)
+			}
+		})
+	}
+}
@@ -6,19 +6,16 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
-	"math/rand"
+	"net/http"
 	"net/url"
 	"time"
 )
 
 var maxResults = 10
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 type Service struct {
 	apiKey      string
 	tokenSource oauth2.TokenSource
@@ -53,22 +50,54 @@ func New(opts ...Option) (*Service, error) {
 	return srvc, nil
 }
 
-func (s *Service) ListComments(videoUrl string, count int) ([]*youtube.CommentSnippet, error) {
+func (s *Service) ListComments(videoUrl string, opts CommentOptions) ([]*CommentThread, error) {
 	videoId, urlErr := parseVideoUrl(videoUrl)
 	if urlErr != nil {
 		return nil, urlErr
 	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return loadComments(func() ([]*CommentThread, error) {
+		commentRequest := s.ytService.CommentThreads.List(opts.part()).VideoId(videoId)
+		return s.getNumberOfComments(commentRequest, opts)
+	})
+}
 
+// ListChannelComments lists the comments on every video of a channel, newest
+// first unless another order is given. Each comment is labeled with the ID
+// and title of its video.
+func (s *Service) ListChannelComments(channelRef string, opts CommentOptions) ([]*CommentThread, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return loadComments(func() ([]*CommentThread, error) {
+		channelId, err := s.resolveChannelId(channelRef)
+		if err != nil {
+			return nil, err
+		}
+		commentRequest := s.ytService.CommentThreads.List(opts.part()).AllThreadsRelatedToChannelId(channelId)
+		comments, err := s.getNumberOfComments(commentRequest, opts)
+		if err != nil {
+			return nil, err
+		}
+		return comments, s.labelVideos(comments)
+	})
+}
+
+func loadComments(load func() ([]*CommentThread, error)) ([]*CommentThread, error) {
 	shutdown := make(chan bool)
 	commChan := make(chan struct {
-		comments []*youtube.CommentSnippet
+		comments []*CommentThread
 		err      error
 	})
 	tickerLogger("loading youtube comments", shutdown)
 	go func() {
-		comments, err := s.getNumberOfComments(videoId, count)
+		comments, err := load()
 		commChan <- struct {
-			comments []*youtube.CommentSnippet
+			comments []*CommentThread
 			err      error
 		}{comments, err}
 		close(shutdown)
@@ -77,104 +106,185 @@ func (s *Service) ListComments(videoUrl string, count int) ([]*youtube.CommentSn
 	return result.comments, result.err
 }
 
-func (s *Service) RandomCommenters(videoUrl string, winnerCount int) ([]string, error) {
-	videoId, urlErr := parseVideoUrl(videoUrl)
-	if urlErr != nil {
-		return nil, urlErr
-	}
-
+// PrepareDraw fetches every top-level comment on the videos and returns a draw
+// record for them that has not been run yet. Each URL is either a video or a
+// playlist, whose videos are all included.
+func (s *Service) PrepareDraw(videoUrls []string, rules Rules, seed string) (*DrawRecord, error) {
 	shutdown := make(chan bool)
 	commChan := make(chan struct {
-		comments []*youtube.CommentSnippet
+		videoIds []string
+		threads  []*youtube.CommentThread
 		err      error
 	})
 	tickerLogger("loading youtube commenters", shutdown)
 	go func() {
-		comments, err := s.getComments(videoId)
+		videoIds, threads, err := s.getVideosComments(videoUrls)
 		commChan <- struct {
-			comments []*youtube.CommentSnippet
+			videoIds []string
+			threads  []*youtube.CommentThread
 			err      error
-		}{comments, err}
+		}{videoIds, threads, err}
 		close(shutdown)
 	}()
 	result := <-commChan
 	if result.err != nil {
 		return nil, result.err
 	}
-	commenters := result.comments
-
-	winners := make([]int, winnerCount)
-	iter := 0
-	for iter < winnerCount {
-		winner := rand.Intn(len(commenters))
-		unique := true
-		for _, val := range winners {
-			if winner == val {
-				unique = false
-				break
+
+	entrants := make([]*Entrant, 0, len(result.threads))
+	for _, thread := range result.threads {
+		entrants = append(entrants, newEntrant(thread))
+	}
+
+	channelId, err := s.videoChannelId(result.videoIds[0])
+	if err != nil {
+		return nil, err
+	}
+
+	record := NewDrawRecord(result.videoIds, entrants, rules, seed)
+	record.ChannelId = channelId
+	return record, s.ScreenChannels(record)
+}
+
+// RunDraw draws the winners of a prepared record, checking every drawn
+// candidate against the eligibility rules. Afterwards the record holds
+// everything needed to recompute the draw with DrawRecord.Verify.
+func (s *Service) RunDraw(record *DrawRecord) error {
+	var check EligibilityCheck
+	if record.Rules.SubscribersOnly {
+		check = s.SubscriberCheck(record.ChannelId, record.Rules.PrivateSubscriptions)
+	}
+	return record.Run(check)
+}
+
+// getVideosComments resolves the video and playlist URLs into distinct video
+// IDs and fetches the comment threads of each of them.
+func (s *Service) getVideosComments(videoUrls []string) ([]string, []*youtube.CommentThread, error) {
+	videoIds := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, videoUrl := range videoUrls {
+		ids, err := s.resolveVideoUrl(videoUrl)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				videoIds = append(videoIds, id)
 			}
 		}
+	}
+	if len(videoIds) == 0 {
+		return nil, nil, errors.New("no videos to draw from")
+	}
 
-		if unique {
-			winners[iter] = winner
-			iter++
+	threads := make([]*youtube.CommentThread, 0)
+	for _, videoId := range videoIds {
+		videoThreads, err := s.getComments(videoId)
+		if err != nil {
+			return nil, nil, fmt.Errorf("video %s: %w", videoId, err)
 		}
+		threads = append(threads, videoThreads...)
 	}
-	winnerNames := make([]string, winnerCount)
-	for i, val := range winners {
-		winnerNames[i] = commenters[val].AuthorDisplayName
+	return videoIds, threads, nil
+}
+
+// resolveVideoUrl returns the video ID of a watch URL, or the IDs of every
+// video in a playlist URL.
+func (s *Service) resolveVideoUrl(videoUrl string) ([]string, error) {
+	playlistId, err := parsePlaylistUrl(videoUrl)
+	if err != nil {
+		return nil, err
+	}
+	if playlistId == "" {
+		videoId, err := parseVideoUrl(videoUrl)
+		if err != nil {
+			return nil, err
+		}
+		if videoId == "" {
+			return nil, InvalidUrlErr
+		}
+		return []string{videoId}, nil
+	}
+
+	videoIds := make([]string, 0)
+	playlistRequest := s.ytService.PlaylistItems.List([]string{"contentDetails"}).PlaylistId(playlistId).MaxResults(50)
+	resp, err := playlistRequest.Do()
+	if err != nil {
+		return nil, fmt.Errorf("playlist %s: %w", playlistId, err)
+	}
+	for _, item := range resp.Items {
+		videoIds = append(videoIds, item.ContentDetails.VideoId)
+	}
+	for resp.NextPageToken != "" {
+		resp, err = playlistRequest.PageToken(resp.NextPageToken).Do()
+		if err != nil {
+			return nil, fmt.Errorf("playlist %s: %w", playlistId, err)
+		}
+		for _, item := range resp.Items {
+			videoIds = append(videoIds, item.ContentDetails.VideoId)
+		}
 	}
 
-	return winnerNames, nil
+	return videoIds, nil
 }
 
-func (s *Service) getComments(videoId string) ([]*youtube.CommentSnippet, error) {
-	comments := make([]*youtube.CommentSnippet, 0)
+func (s *Service) getComments(videoId string) ([]*youtube.CommentThread, error) {
+	threads := make([]*youtube.CommentThread, 0)
 
 	commentRequest := s.ytService.CommentThreads.List([]string{"snippet"}).VideoId(videoId).MaxResults(int64(maxResults))
 	resp, err := commentRequest.Do()
 	if err != nil {
 		return nil, err
 	}
-	for _, i := range resp.Items {
-		comments = append(comments, i.Snippet.TopLevelComment.Snippet)
-	}
+	threads = append(threads, resp.Items...)
 	for resp.NextPageToken != "" {
 		resp, err = commentRequest.PageToken(resp.NextPageToken).Do()
 		if err != nil {
 			return nil, err
 		}
-		for _, i := range resp.Items {
-			comments = append(comments, i.Snippet.TopLevelComment.Snippet)
-		}
+		threads = append(threads, resp.Items...)
 	}
 
-	return comments, nil
+	return threads, nil
 }
 
-func (s *Service) getNumberOfComments(videoId string, count int) ([]*youtube.CommentSnippet, error) {
-	comments := make([]*youtube.CommentSnippet, 0)
+// getNumberOfComments pages through the threads of commentRequest. A count of
+// 0 or less reads every page, and with Since the pages stop at the first one
+// holding only older comments.
+func (s *Service) getNumberOfComments(commentRequest *youtube.CommentThreadsListCall, opts CommentOptions) ([]*CommentThread, error) {
+	comments := make([]*CommentThread, 0)
+	count := opts.Count
 
-	limit := count
-	if count > maxResults {
-		limit = maxResults
+	limit := maxResults
+	if count > 0 && count < maxResults {
+		limit = count
 	}
 
-	commentRequest := s.ytService.CommentThreads.List([]string{"snippet"}).VideoId(videoId)
+	if opts.Order != "" {
+		commentRequest = commentRequest.Order(opts.Order)
+	}
+	if opts.Search != "" {
+		commentRequest = commentRequest.SearchTerms(opts.Search)
+	}
+	if opts.Format != "" {
+		commentRequest = commentRequest.TextFormat(apiTextFormat(opts.Format))
+	}
+	if opts.ModerationStatus != "" {
+		commentRequest = commentRequest.ModerationStatus(opts.ModerationStatus)
+	}
 	resp, err := commentRequest.MaxResults(int64(limit)).Do()
 	if err != nil {
 		return nil, err
 	}
-	for _, i := range resp.Items {
-		comments = append(comments, i.Snippet.TopLevelComment.Snippet)
-	}
-	for resp.NextPageToken != "" {
-		if len(comments) >= count {
+	done := !opts.appendThreads(&comments, resp.Items)
+	for resp.NextPageToken != "" && !done {
+		if count > 0 && len(comments) >= count {
 			break
 		}
 
 		// reset the limit if possible
-		if count-len(comments) < maxResults {
+		if count > 0 && count-len(comments) < maxResults {
 			limit = count - len(comments)
 		}
 
@@ -182,15 +292,18 @@ func (s *Service) getNumberOfComments(videoId string, count int) ([]*youtube.Com
 		if err != nil {
 			return nil, err
 		}
-		for _, i := range resp.Items {
-			comments = append(comments, i.Snippet.TopLevelComment.Snippet)
-		}
+		done = !opts.appendThreads(&comments, resp.Items)
 	}
 
-	if len(comments) < count {
-		return comments, nil
+	if count > 0 && len(comments) > count {
+		comments = comments[0:count]
+	}
+	if opts.Replies {
+		if err := s.completeReplies(comments, opts.Format); err != nil {
+			return nil, err
+		}
 	}
-	return comments[0:count], nil
+	return comments, nil
 }
 
 func tickerLogger(message string, shutdown <-chan bool) {
@@ -227,3 +340,54 @@ func parseVideoUrl(videoUrl string) (string, error) {
 
 	return q.Get("v"), nil
 }
+
+// parsePlaylistUrl returns the list ID of a playlist URL, or an empty string
+// when the URL points to a single video.
+func parsePlaylistUrl(playlistUrl string) (string, error) {
+	u, err := url.ParseRequestURI(playlistUrl)
+	if err != nil {
+		return "", err
+	}
+	if u.Host != "www.youtube.com" {
+		return "", InvalidUrlErr
+	}
+	q, parseErr := url.ParseQuery(u.RawQuery)
+	if parseErr != nil {
+		return "", parseErr
+	}
+	if q.Get("v") != "" {
+		return "", nil
+	}
+
+	return q.Get("list"), nil
+}
+
+// fatalReasons are the API error reasons that fail every later request too.
+var fatalReasons = map[string]bool{
+	"quotaExceeded":         true,
+	"dailyLimitExceeded":    true,
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+}
+
+// IsFatal reports whether err would fail any request that follows, like an
+// exhausted quota or a revoked token, rather than only the one that got it.
+// Errors that did not come from the API, such as network errors, are fatal.
+func IsFatal(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	if apiErr.Code == http.StatusUnauthorized {
+		return true
+	}
+	for _, item := range apiErr.Errors {
+		if fatalReasons[item.Reason] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/api/googleapi"
+	"testing"
+)
+
+func TestIsFatal(t *testing.T) {
+	apiErr := func(code int, reason string) error {
+		return &googleapi.Error{Code: code, Errors: []googleapi.ErrorItem{{Reason: reason}}}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"quota", apiErr(403, "quotaExceeded"), true},
+		{"wrapped quota", fmt.Errorf("could not reply: %w", apiErr(403, "quotaExceeded")), true},
+		{"rate limit", apiErr(403, "userRateLimitExceeded"), true},
+		{"unauthorized", apiErr(401, "authError"), true},
+		{"network", errors.New("connection reset by peer"), true},
+		{"comment not found", apiErr(404, "commentNotFound"), false},
+		{"comments disabled", apiErr(403, "forbidden"), false},
+		{"server error", apiErr(500, "backendError"), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsFatal(test.err); got != test.want {
+				t.Errorf("IsFatal() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}